@@ -0,0 +1,85 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+// An alias map that can't be changed, every change returns a new Alias
+//
+// Alias maps are usually shared by many requests (ie.: loaded from a
+// config file), so a base alias can be composed with the values of each
+// request without changing it:
+//
+//	base := NewAlias(map[string]string{"main": "layout/main.html"})
+//	alias := base.Set("contents", "users/index.html")
+//
+// The zero value is an empty alias map.
+type Alias struct {
+	m map[string]string
+}
+
+// Create an alias with the entries from m, m is copied
+func NewAlias(m map[string]string) Alias {
+	return Alias{}.MergeMap(m)
+}
+
+// Return the target of the given alias
+func (a Alias) Lookup(name string) (string, bool) {
+	v, has := a.m[name]
+	return v, has
+}
+
+// Return the number of entries
+func (a Alias) Len() int {
+	return len(a.m)
+}
+
+// Return a copy of a with name pointing to target
+func (a Alias) Set(name, target string) Alias {
+	return a.MergeMap(map[string]string{name: target})
+}
+
+// Return a copy of a with the entries from other, entries from
+// other override the ones from a
+func (a Alias) Merge(other Alias) Alias {
+	return a.MergeMap(other.m)
+}
+
+// Same as Merge but takes a map
+func (a Alias) MergeMap(other map[string]string) Alias {
+	if len(other) == 0 {
+		return a
+	}
+	m := a.Map()
+	for k, v := range other {
+		m[k] = v
+	}
+	return Alias{m: m}
+}
+
+// Return a copy of a with the entries from other that a
+// don't have
+func (a Alias) Defaults(other Alias) Alias {
+	m := other.Map()
+	for k, v := range a.m {
+		m[k] = v
+	}
+	return Alias{m: m}
+}
+
+// Return the entries as a new map, changing the map don't
+// change a
+func (a Alias) Map() map[string]string {
+	m := make(map[string]string, len(a.m))
+	for k, v := range a.m {
+		m[k] = v
+	}
+	return m
+}
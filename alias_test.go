@@ -0,0 +1,54 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAlias(t *testing.T) {
+	shared := map[string]string{"main": "layout/main.html"}
+	base := NewAlias(shared)
+	shared["main"] = "changed.html"
+	if v, _ := base.Lookup("main"); v != "layout/main.html" {
+		t.Errorf("NewAlias should copy the map but got %v", v)
+	}
+
+	withView := base.Set("contents", "users/index.html")
+	if _, has := base.Lookup("contents"); has || base.Len() != 1 {
+		t.Errorf("Set should not change the base alias")
+	}
+
+	merged := withView.Merge(NewAlias(map[string]string{"main": "admin/main.html", "@head": "x"}))
+	expected := map[string]string{"main": "admin/main.html", "contents": "users/index.html", "@head": "x"}
+	if !reflect.DeepEqual(merged.Map(), expected) {
+		t.Errorf("unexpected merge %v", merged.Map())
+	}
+
+	defaults := withView.Defaults(NewAlias(map[string]string{"main": "admin/main.html", "@head": "x"}))
+	expected = map[string]string{"main": "layout/main.html", "contents": "users/index.html", "@head": "x"}
+	if !reflect.DeepEqual(defaults.Map(), expected) {
+		t.Errorf("unexpected defaults %v", defaults.Map())
+	}
+
+	m := merged.Map()
+	m["main"] = "other.html"
+	if v, _ := merged.Lookup("main"); v != "admin/main.html" {
+		t.Errorf("changing the result of Map should not change the alias")
+	}
+
+	var zero Alias
+	if zero.Len() != 0 || len(zero.Map()) != 0 {
+		t.Errorf("the zero value should be empty")
+	}
+}
@@ -0,0 +1,91 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Create a new VFS from the contents of a zip archive
+//
+// The root of the archive is the root of the vfs.
+func ZipVFS(r *zip.Reader) Dir {
+	return FS(r)
+}
+
+// Create a new VFS from the contents of a tar archive, if the
+// archive is compressed with gzip, it is decompressed automatically.
+//
+// The whole archive is read into memory, only regular files are kept.
+func TarVFS(r io.Reader) (Dir, error) {
+	buf := bufio.NewReader(r)
+	if magic, err := buf.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buf)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buf
+	}
+
+	files := make(MapVFS)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		contents, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[hdr.Name] = contents
+	}
+	return files, nil
+}
+
+// Open the archive at the given path, the format is selected using the
+// file extension (.zip, .tar, .tar.gz or .tgz)
+func OpenArchive(file string) (Dir, error) {
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasSuffix(file, ".zip"):
+		zr, err := zip.NewReader(bytes.NewReader(contents), int64(len(contents)))
+		if err != nil {
+			return nil, err
+		}
+		return ZipVFS(zr), nil
+	case strings.HasSuffix(file, ".tar"),
+		strings.HasSuffix(file, ".tar.gz"),
+		strings.HasSuffix(file, ".tgz"):
+		return TarVFS(bytes.NewReader(contents))
+	}
+	return nil, fmt.Errorf("%v isn't a supported archive", file)
+}
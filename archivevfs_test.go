@@ -0,0 +1,91 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+var archiveFiles = map[string]string{
+	"index.html":        `{{ template "layout/index.html" . }}`,
+	"layout/index.html": `<!doctype html><html></html>`,
+	"layout/readme.txt": `ignored`,
+}
+
+func checkArchiveVFS(t *testing.T, vfs Dir) {
+	set, err := LoadDir(vfs, nil, FilterFunc(AllowHtmlJsAndCss))
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	if len(set) != 2 {
+		t.Errorf("expecting 2 templates but got %v", len(set))
+	}
+	for _, name := range []string{"index.html", "layout/index.html"} {
+		if _, has := set[name]; !has {
+			t.Errorf("%v should be in the set", name)
+		}
+	}
+}
+
+func TestZipVFS(t *testing.T) {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for name, contents := range archiveFiles {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("unable to create zip entry %v", err)
+		}
+		w.Write([]byte(contents))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("unable to close zip %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("unable to read zip %v", err)
+	}
+	checkArchiveVFS(t, ZipVFS(zr))
+}
+
+func TestTarVFS(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		buf := &bytes.Buffer{}
+		var gz *gzip.Writer
+		tw := tar.NewWriter(buf)
+		if compress {
+			gz = gzip.NewWriter(buf)
+			tw = tar.NewWriter(gz)
+		}
+		tw.WriteHeader(&tar.Header{Name: "./layout/", Typeflag: tar.TypeDir, Mode: 0755})
+		for name, contents := range archiveFiles {
+			tw.WriteHeader(&tar.Header{Name: "./" + name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(contents))})
+			tw.Write([]byte(contents))
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatalf("unable to close tar %v", err)
+		}
+		if gz != nil {
+			gz.Close()
+		}
+
+		vfs, err := TarVFS(buf)
+		if err != nil {
+			t.Fatalf("unable to read tar (gzip: %v) %v", compress, err)
+		}
+		checkArchiveVFS(t, vfs)
+	}
+}
@@ -0,0 +1,114 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"path"
+	"strconv"
+)
+
+// The escaping context of a non-html template.
+//
+// html/template always starts in the html text context, to escape
+// a javascript file correctly, the file is executed inside a <script>
+// element and the element is removed from the output.
+type escapeContext struct {
+	contentType string
+	open, close string
+}
+
+var (
+	htmlContentType = "text/html; charset=utf-8"
+	escapeContexts  = map[string]*escapeContext{
+		".js":   {"application/javascript; charset=utf-8", "<script>", "</script>"},
+		".json": {"application/json; charset=utf-8", `<script type="application/json">`, "</script>"},
+		".css":  {"text/css; charset=utf-8", "<style>", "</style>"},
+	}
+)
+
+// Return the name of the wrapper of a template, ie.: _context.js:app.js
+func wrapperName(ext, name string) string {
+	return "_context" + ext + ":" + name
+}
+
+// Return the Content-Type of the output of the given template, based
+// on its extension.
+//
+// Anything that isn't js, json or css is considered html
+func ContentType(name string) string {
+	if ctx := escapeContexts[path.Ext(name)]; ctx != nil {
+		return ctx.contentType
+	}
+	return htmlContentType
+}
+
+// Add one wrapper for every non-html template (or alias)
+func addContextWrappers(t *template.Template, set TreeSet, alias map[string]string) error {
+	for k := range set {
+		if err := addContextWrapper(t, k, k); err != nil {
+			return err
+		}
+	}
+	for k, v := range alias {
+		if _, has := set[v]; has {
+			if err := addContextWrapper(t, k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func addContextWrapper(t *template.Template, name, target string) error {
+	ext := path.Ext(target)
+	ctx := escapeContexts[ext]
+	if ctx == nil {
+		return nil
+	}
+	_, err := t.New(wrapperName(ext, name)).Parse(ctx.open + "{{ template " + strconv.Quote(name) + " . }}" + ctx.close)
+	return err
+}
+
+// Execute the template with the given name escaping its output using
+// the context given by its extension, javascript files are escaped
+// as javascript, css as css and json files are escaped as json
+// (values are encoded using encoding/json).
+//
+// The template must be created by Template or TemplateFuncs.
+//
+// When executing a js/json/css file with ExecuteTemplate from html/template,
+// the output is escaped as if it was inside a html element.
+//
+// Since the output must be changed before being written to w, the whole
+// output of non-html templates is kept in memory.
+func ExecuteContext(t *template.Template, w io.Writer, name string, data interface{}) error {
+	for ext, ctx := range escapeContexts {
+		if wrapper := t.Lookup(wrapperName(ext, name)); wrapper != nil {
+			return executeWrapper(wrapper, ctx, w, data)
+		}
+	}
+	return t.ExecuteTemplate(w, name, data)
+}
+
+func executeWrapper(wrapper *template.Template, ctx *escapeContext, w io.Writer, data interface{}) error {
+	buf := &bytes.Buffer{}
+	if err := wrapper.Execute(buf, data); err != nil {
+		return err
+	}
+	out := bytes.TrimPrefix(buf.Bytes(), []byte(ctx.open))
+	out = bytes.TrimSuffix(out, []byte(ctx.close))
+	_, err := w.Write(out)
+	return err
+}
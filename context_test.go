@@ -0,0 +1,65 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExecuteContext(t *testing.T) {
+	set, err := LoadDir(MapVFS{
+		"app.js":     []byte(`var name = <% .Name %>; var msg = "hi <% .Name %>";`),
+		"style.css":  []byte(`body { font-family: <% .Font %>; }`),
+		"data.json":  []byte(`{"name": <% .Name %>, "tags": <% .Tags %>}`),
+		"index.html": []byte(`<p>{{ .Name }}</p>`),
+	}, nil, FilterFunc(AllowHtmlJsAndCss))
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	tmpl, err := Template(set, map[string]string{"main": "app.js"})
+	if err != nil {
+		t.Fatalf("unable to build template %v", err)
+	}
+
+	data := map[string]interface{}{
+		"Name": `</script>"bob"`,
+		"Font": "x;}",
+		"Tags": []string{"a", "b"},
+	}
+	for name, expected := range map[string]string{
+		"app.js":     `var name = "\u003c/script\u003e\"bob\""; var msg = "hi \u003c\/script\u003e\u0022bob\u0022";`,
+		"main":       `var name = "\u003c/script\u003e\"bob\""; var msg = "hi \u003c\/script\u003e\u0022bob\u0022";`,
+		"style.css":  `body { font-family: ZgotmplZ; }`,
+		"data.json":  `{"name": "\u003c/script\u003e\"bob\"", "tags": ["a","b"]}`,
+		"index.html": `<p>&lt;/script&gt;&#34;bob&#34;</p>`,
+	} {
+		buf := &bytes.Buffer{}
+		if err := ExecuteContext(tmpl, buf, name, data); err != nil {
+			t.Fatalf("unable to render %v: %v", name, err)
+		}
+		if buf.String() != expected {
+			t.Errorf("%v should render\n%v\nbut got\n%v", name, expected, buf.String())
+		}
+	}
+
+	for name, ct := range map[string]string{
+		"app.js":     "application/javascript; charset=utf-8",
+		"data.json":  "application/json; charset=utf-8",
+		"a/b.css":    "text/css; charset=utf-8",
+		"index.html": "text/html; charset=utf-8",
+	} {
+		if ContentType(name) != ct {
+			t.Errorf("%v should be %v but got %v", name, ct, ContentType(name))
+		}
+	}
+}
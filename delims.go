@@ -0,0 +1,94 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"path"
+	"strings"
+)
+
+// Map file names to the delimiters used to parse them
+//
+// A pattern can be a extension (".svg") or a glob accepted by path.Match.
+// Globs without a "/" are matched against the file name ("*.vue.html"),
+// the others against the full template name ("vue/*.html").
+//
+// When more than one pattern matches, the last one registered wins, so
+// it's possible to start from DefaultDelims and override some rules:
+//
+//	delims := DefaultDelims().
+//		Register(".svg", "<%", "%>").
+//		Register("vue/*.html", "[[", "]]")
+type Delims struct {
+	rules []delimRule
+}
+
+type delimRule struct {
+	pattern     string
+	left, right string
+}
+
+// Return a new registry with the rules used by DiscoverDelim
+func DefaultDelims() *Delims {
+	return (&Delims{}).
+		Register(".html", "{{", "}}").
+		Register(".js", "<%", "%>").
+		Register(".json", "<%", "%>").
+		Register(".css", "<%", "%>")
+}
+
+// the rules used when nothing is informed
+var defaultDelims = DefaultDelims()
+
+// Register the delimiters for the given pattern
+func (d *Delims) Register(pattern, left, right string) *Delims {
+	d.rules = append(d.rules, delimRule{pattern: pattern, left: left, right: right})
+	return d
+}
+
+// Return the delimiters for the given name, if no rule matches
+// "{{" and "}}" are returned
+func (d *Delims) Lookup(name string) (string, string) {
+	if rule := d.match(name); rule != nil {
+		return rule.left, rule.right
+	}
+	return "{{", "}}"
+}
+
+// Filter that allow directories and files that match any
+// of the registered patterns
+func (d *Delims) Allow(f Namer) bool {
+	if f.IsDir() {
+		return true
+	}
+	return d.match(TemplateName(f)) != nil
+}
+
+func (d *Delims) match(name string) *delimRule {
+	for i := len(d.rules) - 1; i >= 0; i-- {
+		if d.rules[i].matches(name) {
+			return &d.rules[i]
+		}
+	}
+	return nil
+}
+
+func (r *delimRule) matches(name string) bool {
+	if !strings.ContainsAny(r.pattern, "*?[/") {
+		return strings.HasSuffix(name, r.pattern)
+	}
+	if !strings.Contains(r.pattern, "/") {
+		name = path.Base(name)
+	}
+	ok, _ := path.Match(r.pattern, name)
+	return ok
+}
@@ -0,0 +1,70 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDefaultDelims(t *testing.T) {
+	for name, left := range map[string]string{
+		"index.html":    "{{",
+		"app.js":        "<%",
+		"data.json":     "<%",
+		"css/style.css": "<%",
+		"readme.txt":    "{{",
+	} {
+		l, _ := DiscoverDelim(name)
+		if l != left {
+			t.Errorf("%v should use %v but got %v", name, left, l)
+		}
+	}
+}
+
+func TestDelims(t *testing.T) {
+	delims := DefaultDelims().
+		Register(".svg", "<%", "%>").
+		Register("vue/*.html", "[[", "]]")
+
+	vfs := MapVFS{
+		"icons/logo.svg": []byte(`<svg><% .Color %></svg>`),
+		"vue/app.html":   []byte(`<div>{{ msg }}</div>[[ .Title ]]`),
+		"index.html":     []byte(`{{ .Title }}`),
+		"readme.txt":     []byte(`not loaded`),
+	}
+	set, err := LoadDirWith(vfs, nil, FilterFunc(delims.Allow), &Options{Delims: delims})
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	if _, has := set["readme.txt"]; has {
+		t.Errorf("readme.txt doesn't match any rule")
+	}
+
+	tmpl, err := Template(set, nil)
+	if err != nil {
+		t.Fatalf("unable to build template %v", err)
+	}
+	for name, expected := range map[string]string{
+		"icons/logo.svg": "<svg>red</svg>",
+		"vue/app.html":   "<div>{{ msg }}</div>red",
+		"index.html":     "red",
+	} {
+		buf := &bytes.Buffer{}
+		if err := tmpl.ExecuteTemplate(buf, name, map[string]string{"Color": "red", "Title": "red"}); err != nil {
+			t.Fatalf("unable to render %v: %v", name, err)
+		}
+		if buf.String() != expected {
+			t.Errorf("%v should render %q but got %q", name, expected, buf.String())
+		}
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // use the filepath to implement the vfs
@@ -111,3 +112,11 @@ func (vfs *diskvfs) Contents() ([]byte, error) {
 	defer f.Close()
 	return ioutil.ReadAll(f)
 }
+
+func (vfs *diskvfs) ModTime() (time.Time, error) {
+	stat, err := os.Stat(vfs.cd)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return stat.ModTime(), nil
+}
@@ -0,0 +1,188 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template/parse"
+)
+
+// Metadata declared at the top of a template file
+//
+// The front matter starts at the first line of the file and is
+// enclosed by two "---" lines, the contents can be a JSON object
+// or a list of "key: value" lines:
+//
+//	---
+//	title: Users
+//	layout: admin/main.html
+//	requires: Users, Page
+//	---
+//	<ul>{{ range .Users }}...{{ end }}</ul>
+//
+// The front matter is removed before the file is parsed, line numbers
+// reported by the template engine still match the file. If the block
+// isn't closed or isn't made of "key: value" lines, the file is loaded
+// as it is, but a block with invalid JSON makes the load fail.
+//
+// The front matter is kept in the tree of the file, so it goes wherever
+// the set goes, use MetaOf to read it.
+type Meta struct {
+	// The title of the page
+	Title string
+	// The layout used to render the template
+	Layout string
+	// The Content-Type of the output
+	ContentType string
+	// The keys that the template expects to find on its data
+	Requires []string
+	// Every key found in the front matter, including the
+	// ones above
+	Values map[string]string
+}
+
+const (
+	frontMatterFence = "---"
+	// starts the comment that keeps the front matter inside the tree
+	metaComment = "/*webview:meta "
+)
+
+// Split the front matter from the rest of the file, if the file don't
+// have a front matter, meta is nil and body is the original text.
+//
+// A block that isn't closed, isn't made of "key: value" lines or is empty
+// isn't a front matter, so files that start with a "---" line (ie.: a
+// horizontal rule) are loaded as they are. A block starting with "{" must
+// be valid JSON, otherwise an error is returned.
+//
+// The front matter is replaced by a comment using the given delimiters,
+// holding the values as json (see MetaOf).
+func splitFrontMatter(text, leftDelim, rightDelim string) (meta *Meta, body string, err error) {
+	first, rest := cutLine(text)
+	if strings.TrimSpace(first) != frontMatterFence {
+		return nil, text, nil
+	}
+	var block []string
+	for len(rest) > 0 {
+		var line string
+		line, rest = cutLine(rest)
+		if strings.TrimSpace(line) == frontMatterFence {
+			raw := strings.Join(block, "\n")
+			meta, err := parseMeta(raw)
+			if err != nil && strings.HasPrefix(strings.TrimSpace(raw), "{") {
+				return nil, text, err
+			}
+			if err != nil || len(meta.Values) == 0 {
+				return nil, text, nil
+			}
+			consumed := text[:len(text)-len(rest)]
+			comment := leftDelim + metaComment + encodeMeta(meta) + strings.Repeat("\n", strings.Count(consumed, "\n")) + "*/" + rightDelim
+			return meta, comment + rest, nil
+		}
+		block = append(block, line)
+	}
+	return nil, text, nil
+}
+
+// Return the first line (without the line break) and the rest of text
+func cutLine(text string) (string, string) {
+	if idx := strings.Index(text, "\n"); idx >= 0 {
+		return strings.TrimSuffix(text[:idx], "\r"), text[idx+1:]
+	}
+	return text, ""
+}
+
+func parseMeta(block string) (*Meta, error) {
+	values := make(map[string]string)
+	if trimmed := strings.TrimSpace(block); strings.HasPrefix(trimmed, "{") {
+		raw := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(trimmed), &raw); err != nil {
+			return nil, err
+		}
+		for k, v := range raw {
+			switch v := v.(type) {
+			case string:
+				values[k] = v
+			case []interface{}:
+				items := make([]string, len(v))
+				for i, item := range v {
+					items[i] = fmt.Sprint(item)
+				}
+				values[k] = strings.Join(items, ", ")
+			default:
+				values[k] = fmt.Sprint(v)
+			}
+		}
+	} else {
+		for i, line := range strings.Split(block, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			idx := strings.Index(line, ":")
+			if idx < 0 {
+				return nil, fmt.Errorf("line %v: expecting key: value", i+2)
+			}
+			values[strings.TrimSpace(line[:idx])] = strings.TrimSpace(line[idx+1:])
+		}
+	}
+	return newMeta(values), nil
+}
+
+func newMeta(values map[string]string) *Meta {
+	meta := &Meta{
+		Title:       values["title"],
+		Layout:      values["layout"],
+		ContentType: values["content-type"],
+		Values:      values,
+	}
+	if requires := values["requires"]; requires != "" {
+		for _, r := range strings.Split(requires, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				meta.Requires = append(meta.Requires, r)
+			}
+		}
+	}
+	return meta
+}
+
+// Return the values as a single line of json that can be placed
+// inside a comment
+func encodeMeta(meta *Meta) string {
+	buf, _ := json.Marshal(meta.Values)
+	// "*/" can only appear inside a json string
+	return strings.Replace(string(buf), "*/", `*\u002f`, -1)
+}
+
+// Return the front matter of the template from set, nil if the template
+// isn't in set or don't have a front matter.
+//
+// Templates from every set loaded by this package carry their front
+// matter, no Index is required.
+func MetaOf(set TreeSet, name string) *Meta {
+	tree := set[name]
+	if tree == nil || tree.Root == nil || len(tree.Root.Nodes) == 0 {
+		return nil
+	}
+	comment, ok := tree.Root.Nodes[0].(*parse.CommentNode)
+	if !ok || !strings.HasPrefix(comment.Text, metaComment) {
+		return nil
+	}
+	values := make(map[string]string)
+	text := strings.TrimSuffix(comment.Text[len(metaComment):], "*/")
+	if err := json.Unmarshal([]byte(text), &values); err != nil {
+		return nil
+	}
+	return newMeta(values)
+}
@@ -0,0 +1,83 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFrontMatter(t *testing.T) {
+	opts := &Options{Index: make(Index)}
+	set, err := LoadDirWith(MapVFS{
+		"users/list.html": []byte("---\ntitle: Users\nlayout: admin/main.html\nrequires: Users, Page\n---\n<ul>\n{{ .Users }}</ul>"),
+		"data.json":       []byte("---\n{\"content-type\": \"application/vnd.api+json\", \"requires\": [\"Items\"]}\n---\n[<% .Items %>]"),
+		"index.html":      []byte("--- not front matter"),
+		"admin/main.html": []byte(`{{ template "contents" . }}`),
+	}, nil, nil, opts)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+
+	meta := opts.Index["users/list.html"].Meta
+	if meta == nil {
+		t.Fatalf("users/list.html should have a front matter")
+	}
+	if meta.Title != "Users" || meta.Layout != "admin/main.html" || !reflect.DeepEqual(meta.Requires, []string{"Users", "Page"}) {
+		t.Errorf("unexpected meta %#v", meta)
+	}
+	meta = opts.Index["data.json"].Meta
+	if meta == nil || meta.ContentType != "application/vnd.api+json" || !reflect.DeepEqual(meta.Requires, []string{"Items"}) {
+		t.Errorf("unexpected meta %#v", meta)
+	}
+	if opts.Index["index.html"].Meta != nil {
+		t.Errorf("index.html don't have a front matter")
+	}
+
+	tmpl, err := Template(set, nil)
+	if err != nil {
+		t.Fatalf("unable to build template %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.ExecuteTemplate(buf, "users/list.html", map[string]string{"Users": "bob"}); err != nil {
+		t.Fatalf("unable to render %v", err)
+	}
+	if buf.String() != "<ul>\nbob</ul>" {
+		t.Errorf("the front matter should be removed but got %q", buf.String())
+	}
+
+	_, err = LoadDir(MapVFS{"index.html": []byte("---\ntitle: x\n---\n\n{{ broken")}, nil, nil)
+	if err == nil || err.Error() != `template: index.html:5: function "broken" not defined` {
+		t.Errorf("line numbers should match the file but got %v", err)
+	}
+	_, err = LoadDir(MapVFS{"index.html": []byte("---\n{\"title\": \"x\",}\n---\nindex")}, nil, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "file index.html has an invalid front matter: ") {
+		t.Errorf("invalid json should fail but got %v", err)
+	}
+	// files that start with a horizontal rule aren't front matter
+	for _, text := range []string{"---\ntitle: x\n", "---\n<p>hi</p>\n---\n", "---\n\n---\n"} {
+		opts := &Options{Index: make(Index)}
+		set, err := LoadDirWith(MapVFS{"index.md": []byte(text)}, nil, nil, opts)
+		if err != nil {
+			t.Errorf("%q should be loaded as it is but got %v", text, err)
+			continue
+		}
+		buf := &bytes.Buffer{}
+		tmpl, _ := Template(set, nil)
+		tmpl.ExecuteTemplate(buf, "index.md", nil)
+		if opts.Index["index.md"].Meta != nil || buf.String() != text {
+			t.Errorf("%q should be loaded as it is but got %q", text, buf.String())
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// use a io/fs.FS to implement the vfs
+type fsvfs struct {
+	fsys   fs.FS
+	cd     string
+	isfile bool
+}
+
+// Create a new VFS using fsys as the root.
+//
+// Any fs.FS can be used (embed.FS, fstest.MapFS, zip.Reader, os.DirFS...),
+// names are the same ones that DiskVFS would return for the same tree.
+func FS(fsys fs.FS) Dir {
+	return &fsvfs{fsys: fsys, cd: ".", isfile: false}
+}
+
+func (vfs *fsvfs) IsDir() bool {
+	return !vfs.isfile
+}
+
+func (vfs *fsvfs) Name() string {
+	if vfs.cd == "." {
+		return ""
+	}
+	return path.Base(vfs.cd)
+}
+
+func (vfs *fsvfs) Parent() Dir {
+	if vfs.cd == "." {
+		return nil
+	}
+	return &fsvfs{fsys: vfs.fsys, cd: path.Dir(vfs.cd), isfile: false}
+}
+
+func (vfs *fsvfs) ReadDirs() ([]Dir, error) {
+	childs, err := fs.ReadDir(vfs.fsys, vfs.cd)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Dir, 0, len(childs))
+	for _, v := range childs {
+		if !v.IsDir() {
+			continue
+		}
+		ret = append(ret, &fsvfs{fsys: vfs.fsys, cd: path.Join(vfs.cd, v.Name())})
+	}
+	return ret, nil
+}
+
+func (vfs *fsvfs) ReadFiles() ([]File, error) {
+	childs, err := fs.ReadDir(vfs.fsys, vfs.cd)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]File, 0, len(childs))
+	for _, v := range childs {
+		if v.IsDir() {
+			continue
+		}
+		ret = append(ret, &fsvfs{fsys: vfs.fsys, cd: path.Join(vfs.cd, v.Name()), isfile: true})
+	}
+	return ret, nil
+}
+
+func (vfs *fsvfs) Contents() ([]byte, error) {
+	return fs.ReadFile(vfs.fsys, vfs.cd)
+}
+
+func (vfs *fsvfs) ModTime() (time.Time, error) {
+	stat, err := fs.Stat(vfs.fsys, vfs.cd)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return stat.ModTime(), nil
+}
+
+// Expose the given Dir as a io/fs.FS
+//
+// Paths are the same returned by TemplateName, so "layout/main.html"
+// opens the file "main.html" inside the "layout" directory.
+func AsFS(root Dir) fs.FS {
+	return &dirFS{root: root}
+}
+
+// implements fs.FS on top of a Dir
+type dirFS struct {
+	root Dir
+}
+
+func (d *dirFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &dirFile{dir: d.root, name: "."}, nil
+	}
+	parts := strings.Split(name, "/")
+	cd := d.root
+	for _, p := range parts[:len(parts)-1] {
+		next, err := findDir(cd, p)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		cd = next
+	}
+	last := parts[len(parts)-1]
+	if dir, err := findDir(cd, last); err == nil {
+		return &dirFile{dir: dir, name: last}, nil
+	}
+	files, err := cd.ReadFiles()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	for _, f := range files {
+		if f.Name() != last {
+			continue
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &regularFile{
+			Reader: bytes.NewReader(contents),
+			info:   fileInfo{name: last, size: int64(len(contents))},
+		}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// search for a sub-directory with the given name
+func findDir(parent Dir, name string) (Dir, error) {
+	dirs, err := parent.ReadDirs()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dirs {
+		if d.Name() == name {
+			return d, nil
+		}
+	}
+	return nil, fs.ErrNotExist
+}
+
+// implements fs.FileInfo and fs.DirEntry
+type fileInfo struct {
+	name  string
+	size  int64
+	isdir bool
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fileInfo) IsDir() bool        { return fi.isdir }
+func (fi fileInfo) Sys() interface{}   { return nil }
+
+func (fi fileInfo) Mode() fs.FileMode {
+	if fi.isdir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (fi fileInfo) Type() fs.FileMode          { return fi.Mode().Type() }
+func (fi fileInfo) Info() (fs.FileInfo, error) { return fi, nil }
+
+// implements fs.DirEntry for a File, the contents are only read
+// when Info is called
+type fileEntry struct {
+	file File
+}
+
+func (fe fileEntry) Name() string      { return fe.file.Name() }
+func (fe fileEntry) IsDir() bool       { return false }
+func (fe fileEntry) Type() fs.FileMode { return 0 }
+
+func (fe fileEntry) Info() (fs.FileInfo, error) {
+	contents, err := fe.file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return fileInfo{name: fe.file.Name(), size: int64(len(contents))}, nil
+}
+
+// a fs.File holding the contents of a File
+type regularFile struct {
+	*bytes.Reader
+	info fileInfo
+}
+
+func (f *regularFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *regularFile) Close() error               { return nil }
+
+// a fs.ReadDirFile backed by a Dir
+type dirFile struct {
+	dir     Dir
+	name    string
+	entries []fs.DirEntry
+	loaded  bool
+}
+
+func (f *dirFile) Stat() (fs.FileInfo, error) {
+	return fileInfo{name: f.name, isdir: true}, nil
+}
+
+func (f *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrInvalid}
+}
+
+func (f *dirFile) Close() error { return nil }
+
+func (f *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !f.loaded {
+		if err := f.load(); err != nil {
+			return nil, err
+		}
+	}
+	if n <= 0 {
+		ret := f.entries
+		f.entries = nil
+		return ret, nil
+	}
+	if len(f.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(f.entries) {
+		n = len(f.entries)
+	}
+	ret := f.entries[:n]
+	f.entries = f.entries[n:]
+	return ret, nil
+}
+
+func (f *dirFile) load() error {
+	files, err := f.dir.ReadFiles()
+	if err != nil {
+		return err
+	}
+	dirs, err := f.dir.ReadDirs()
+	if err != nil {
+		return err
+	}
+	entries := make([]fs.DirEntry, 0, len(files)+len(dirs))
+	for _, v := range files {
+		entries = append(entries, fileEntry{file: v})
+	}
+	for _, v := range dirs {
+		entries = append(entries, fileInfo{name: v.Name(), isdir: true})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	f.entries = entries
+	f.loaded = true
+	return nil
+}
@@ -0,0 +1,121 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSVFS(t *testing.T) {
+	vfs := FS(fstest.MapFS{
+		"index.html":       &fstest.MapFile{Data: []byte(`{{ template "layout/main.html" . }}`)},
+		"layout/main.html": &fstest.MapFile{Data: []byte(`<html></html>`)},
+	})
+
+	if !vfs.IsDir() || vfs.Name() != "" || vfs.Parent() != nil {
+		t.Errorf("root should be a nameless directory without parent")
+	}
+
+	dirs, err := vfs.ReadDirs()
+	if err != nil {
+		t.Fatalf("unable to read vfs dirs %v", err)
+	}
+	if len(dirs) != 1 || dirs[0].Name() != "layout" {
+		t.Fatalf("expecting only the layout dir but got %v", dirs)
+	}
+
+	files, err := dirs[0].ReadFiles()
+	if err != nil {
+		t.Fatalf("unable to read child files %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expecting one file but got %v", len(files))
+	}
+	if name := TemplateName(files[0]); name != "layout/main.html" {
+		t.Errorf("template name should be layout/main.html but got %v", name)
+	}
+
+	set, err := LoadDir(vfs, nil, FilterFunc(AllowHtmlJsAndCss))
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	tmpl, err := Template(set, nil)
+	if err != nil {
+		t.Fatalf("unable to build template %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.ExecuteTemplate(buf, "index.html", nil); err != nil {
+		t.Fatalf("unexpected error while rendering template %v", err)
+	}
+	if buf.String() != "<html></html>" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestAsFS(t *testing.T) {
+	vfs := FS(fstest.MapFS{
+		"index.html":       &fstest.MapFile{Data: []byte("index")},
+		"layout/main.html": &fstest.MapFile{Data: []byte("main")},
+	})
+	if err := fstest.TestFS(AsFS(vfs), "index.html", "layout/main.html"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// counts the files read from a Dir
+type countingDir struct {
+	Dir
+	reads *int
+}
+
+func (d countingDir) ReadFiles() ([]File, error) {
+	files, err := d.Dir.ReadFiles()
+	for i, f := range files {
+		files[i] = countingFile{f, d.reads}
+	}
+	return files, err
+}
+
+type countingFile struct {
+	File
+	reads *int
+}
+
+func (f countingFile) Contents() ([]byte, error) {
+	*f.reads++
+	return f.File.Contents()
+}
+
+func TestAsFSReadDirIsLazy(t *testing.T) {
+	reads := 0
+	fsys := AsFS(countingDir{FS(fstest.MapFS{
+		"a.html": &fstest.MapFile{Data: []byte("aaa")},
+		"b.html": &fstest.MapFile{Data: []byte("b")},
+	}), &reads})
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("unexpected entries %v %v", entries, err)
+	}
+	if reads != 0 {
+		t.Errorf("listing a directory should not read the files but got %v reads", reads)
+	}
+	info, err := entries[0].Info()
+	if err != nil || info.Size() != 3 {
+		t.Errorf("unexpected info %v %v", info, err)
+	}
+	if reads != 1 {
+		t.Errorf("only the file asked for should be read but got %v reads", reads)
+	}
+}
@@ -0,0 +1,50 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	tt "text/template"
+)
+
+var errNotBound = errors.New("function can only be called from templates created by webview.Template")
+
+// Functions available to every template, the real implementation
+// is added by TemplateFuncs
+var builtinFuncs = tt.FuncMap{
+	"partial": func(name string, args ...interface{}) (template.HTML, error) {
+		return "", errNotBound
+	},
+	"yield": func(name string, data ...interface{}) (template.HTML, error) {
+		return "", errNotBound
+	},
+}
+
+// Add the builtin functions to t
+func bindFuncs(t *template.Template) {
+	t.Funcs(template.FuncMap{
+		"partial": partialFunc(t),
+		"yield":   yieldFunc(t),
+	})
+}
+
+// Execute the template and return its output as html, the output
+// was already escaped by t
+func executeHTML(t *template.Template, name string, data interface{}) (template.HTML, error) {
+	buf := &bytes.Buffer{}
+	if err := t.ExecuteTemplate(buf, name, data); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
@@ -0,0 +1,523 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// A value that failed to bind or to validate
+type FieldError struct {
+	// The name of the value, ie.: address.city or items[0].name
+	Field string
+	// The rule that failed: required, min, max, len, pattern, oneof
+	// or type if the value can't be converted
+	Rule string
+	// The message that can be shown to the user
+	Message string
+}
+
+func (f *FieldError) Error() string {
+	return f.Field + " " + f.Message
+}
+
+// The errors returned by Bind, one for each field
+type BindErrors []*FieldError
+
+func (b BindErrors) Error() string {
+	msgs := make([]string, len(b))
+	for i, e := range b {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Return the error of the given field, nil if the field is valid.
+//
+// Useful to show the errors again in the form:
+//
+//	{{ with .Errors.Get "email" }}<span class="error">{{ .Message }}</span>{{ end }}
+func (b BindErrors) Get(field string) *FieldError {
+	for _, e := range b {
+		if e.Field == field {
+			return e
+		}
+	}
+	return nil
+}
+
+// Fill the struct pointed by dst with the values and validate them.
+//
+// The name of each value comes from the form tag or the field name, fields
+// tagged with "-" are ignored, like fields of other types (ie.: maps)
+// without a form tag. Nested structs use the name as prefix
+// (address.city), slices of structs use an index (items[0].name) and slices
+// of other types read all the values with the same name (tags or tags[]):
+//
+//	type Item struct {
+//		Name string `form:"name" validate:"required,max=40"`
+//		Qty  int    `form:"qty" validate:"min=1"`
+//	}
+//	type Order struct {
+//		Email  string   `form:"email" validate:"required,pattern=^[^@]+@[^@]+$"`
+//		Status string   `form:"status" validate:"oneof=draft|sent"`
+//		Tags   []string `form:"tags" validate:"max=5"`
+//		Items  []Item   `form:"items" validate:"required"`
+//	}
+//
+// Rules, separated by commas:
+//
+//	required    the value must be present and not empty, nested structs
+//	            and slices need at least one value
+//	min=N       the minimum value of numbers, length of strings or size of slices
+//	max=N       same as min but for the maximum
+//	len=N       the exact length of strings or size of slices
+//	pattern=RE  the value must match the regexp, must be the last rule
+//	oneof=a|b   the value must be one of the options
+//
+// Fields without a value are left unchanged, so dst can hold the defaults,
+// and only the required rule is checked for them. Strings, bools, numbers,
+// pointers to them and encoding.TextUnmarshaler are supported.
+//
+// When a value is invalid, a BindErrors is returned after all fields are
+// processed.
+func (r *Reader) Bind(dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("httpview: Bind needs a pointer to a struct, got %T", dst)
+	}
+	var errs BindErrors
+	if err := r.bindStruct(rv.Elem(), "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (r *Reader) bindStruct(v reflect.Value, prefix string, errs *BindErrors) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("form")
+		if tag == "-" {
+			continue
+		}
+		if sf.Anonymous && tag == "" && sf.Type.Kind() == reflect.Struct {
+			// embedded structs share the prefix, even if the
+			// type is unexported its fields might be exported
+			if err := r.bindStruct(v.Field(i), prefix, errs); err != nil {
+				return err
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			// unexported
+			continue
+		}
+		name := sf.Name
+		if tag != "" {
+			name = tag
+		}
+		if tag == "" && !bindable(sf.Type) {
+			// ie.: a map kept by a view model
+			continue
+		}
+		rules, err := parseRules(sf.Tag.Get("validate"))
+		if err != nil {
+			return fmt.Errorf("httpview: field %v.%v: %v", t.Name(), sf.Name, err)
+		}
+		if err := r.bindField(v.Field(i), prefix+name, rules, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Reader) bindField(f reflect.Value, name string, rules []*rule, errs *BindErrors) error {
+	if isScalar(f.Type()) {
+		values := r.Values[name]
+		if len(values) == 0 || values[0] == "" {
+			if hasRule(rules, "required") {
+				*errs = append(*errs, requiredError(name))
+			}
+			return nil
+		}
+		if err := setScalar(f, values[0]); err != nil {
+			*errs = append(*errs, &FieldError{Field: name, Rule: "type", Message: err.Error()})
+			return nil
+		}
+		if ferr := checkRules(name, rules, values[0], f); ferr != nil {
+			*errs = append(*errs, ferr)
+		}
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.Struct:
+		if hasRule(rules, "required") && !r.hasPrefix(name+".") {
+			*errs = append(*errs, requiredError(name))
+			return nil
+		}
+		return r.bindStruct(f, name+".", errs)
+	case reflect.Ptr:
+		if f.Type().Elem().Kind() != reflect.Struct {
+			break
+		}
+		if !r.hasPrefix(name + ".") {
+			if hasRule(rules, "required") {
+				*errs = append(*errs, requiredError(name))
+			}
+			return nil
+		}
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		return r.bindStruct(f.Elem(), name+".", errs)
+	case reflect.Slice:
+		return r.bindSlice(f, name, rules, errs)
+	}
+	return fmt.Errorf("httpview: unable to bind %v of type %v", name, f.Type())
+}
+
+func (r *Reader) bindSlice(f reflect.Value, name string, rules []*rule, errs *BindErrors) error {
+	elem := f.Type().Elem()
+	var slice reflect.Value
+	if isScalar(elem) {
+		values := r.Values[name]
+		if len(values) == 0 {
+			values = r.Values[name+"[]"]
+		}
+		if len(values) == 0 {
+			if hasRule(rules, "required") {
+				*errs = append(*errs, requiredError(name))
+			}
+			return nil
+		}
+		slice = reflect.MakeSlice(f.Type(), len(values), len(values))
+		for i, v := range values {
+			item := fmt.Sprintf("%v[%d]", name, i)
+			if err := setScalar(slice.Index(i), v); err != nil {
+				*errs = append(*errs, &FieldError{Field: item, Rule: "type", Message: err.Error()})
+			} else if ferr := checkItemRules(item, rules, v, slice.Index(i)); ferr != nil {
+				*errs = append(*errs, ferr)
+			}
+		}
+	} else {
+		indexes := r.indexes(name)
+		if len(indexes) == 0 {
+			if hasRule(rules, "required") {
+				*errs = append(*errs, requiredError(name))
+			}
+			return nil
+		}
+		slice = reflect.MakeSlice(f.Type(), len(indexes), len(indexes))
+		for i, idx := range indexes {
+			if err := r.bindField(slice.Index(i), fmt.Sprintf("%v[%d]", name, idx), nil, errs); err != nil {
+				return err
+			}
+		}
+	}
+	f.Set(slice)
+	if ferr := checkSize(name, rules, slice.Len(), "items"); ferr != nil {
+		*errs = append(*errs, ferr)
+	}
+	return nil
+}
+
+// Check if any value starts with prefix
+func (r *Reader) hasPrefix(prefix string) bool {
+	for k := range r.Values {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the sorted indexes used by name[N].field
+func (r *Reader) indexes(name string) []int {
+	seen := make(map[int]bool)
+	var ret []int
+	for k := range r.Values {
+		if !strings.HasPrefix(k, name+"[") {
+			continue
+		}
+		rest := k[len(name)+1:]
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			continue
+		}
+		idx, err := strconv.Atoi(rest[:end])
+		if err != nil || idx < 0 || seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		ret = append(ret, idx)
+	}
+	sort.Ints(ret)
+	return ret
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Check if the type is read from a single value
+// Check if bindField supports the type
+func bindable(t reflect.Type) bool {
+	if isScalar(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Ptr:
+		return t.Elem().Kind() == reflect.Struct
+	case reflect.Slice:
+		return bindable(t.Elem())
+	}
+	return false
+}
+
+func isScalar(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Ptr:
+		return isScalar(t.Elem())
+	}
+	return false
+}
+
+// Convert the value and store it in f
+func setScalar(f reflect.Value, value string) error {
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		return setScalar(f.Elem(), value)
+	}
+	if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("is invalid")
+		}
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		if value == "on" {
+			// sent by checkboxes without a value
+			f.SetBool(true)
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		f.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a positive integer")
+		}
+		f.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		f.SetFloat(n)
+	}
+	return nil
+}
+
+// A validation rule from the validate tag
+type rule struct {
+	name string
+	arg  string
+	// the parsed arg of min/max/len
+	n float64
+	// the parsed arg of pattern
+	re *regexp.Regexp
+	// the options of oneof
+	options []string
+}
+
+// parsed rules by tag, the tags never change
+var rulesCache sync.Map
+
+func parseRules(tag string) ([]*rule, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	if cached, ok := rulesCache.Load(tag); ok {
+		return cached.([]*rule), nil
+	}
+	var rules []*rule
+	rest := tag
+	for rest != "" {
+		var part string
+		if strings.HasPrefix(rest, "pattern=") {
+			// the regexp might have commas
+			part, rest = rest, ""
+		} else if idx := strings.Index(rest, ","); idx >= 0 {
+			part, rest = rest[:idx], rest[idx+1:]
+		} else {
+			part, rest = rest, ""
+		}
+		r := &rule{name: strings.TrimSpace(part)}
+		if idx := strings.Index(part, "="); idx >= 0 {
+			r.name, r.arg = strings.TrimSpace(part[:idx]), part[idx+1:]
+		}
+		switch r.name {
+		case "required":
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(r.arg, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %v rule: %v", r.name, r.arg)
+			}
+			r.n = n
+		case "pattern":
+			re, err := regexp.Compile(r.arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern rule: %v", err)
+			}
+			r.re = re
+		case "oneof":
+			r.options = strings.Split(r.arg, "|")
+		default:
+			return nil, fmt.Errorf("unknown rule %v", r.name)
+		}
+		rules = append(rules, r)
+	}
+	rulesCache.Store(tag, rules)
+	return rules, nil
+}
+
+func hasRule(rules []*rule, name string) bool {
+	for _, r := range rules {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func requiredError(name string) *FieldError {
+	return &FieldError{Field: name, Rule: "required", Message: "is required"}
+}
+
+// Check the rules against a single value, raw is the value as sent by
+// the client and f the converted value
+func checkRules(name string, rules []*rule, raw string, f reflect.Value) *FieldError {
+	for f.Kind() == reflect.Ptr {
+		f = f.Elem()
+	}
+	var num float64
+	isNum := true
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num = float64(f.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num = float64(f.Uint())
+	case reflect.Float32, reflect.Float64:
+		num = f.Float()
+	default:
+		isNum = false
+	}
+	for _, r := range rules {
+		switch r.name {
+		case "min", "max", "len":
+			if !isNum {
+				if ferr := checkSize(name, []*rule{r}, utf8.RuneCountInString(raw), "characters"); ferr != nil {
+					return ferr
+				}
+			} else if r.name == "min" && num < r.n {
+				return &FieldError{Field: name, Rule: r.name, Message: fmt.Sprintf("must be at least %v", r.arg)}
+			} else if r.name == "max" && num > r.n {
+				return &FieldError{Field: name, Rule: r.name, Message: fmt.Sprintf("must be at most %v", r.arg)}
+			} else if r.name == "len" && num != r.n {
+				return &FieldError{Field: name, Rule: r.name, Message: fmt.Sprintf("must be %v", r.arg)}
+			}
+		case "pattern", "oneof":
+			if ferr := checkValue(name, r, raw); ferr != nil {
+				return ferr
+			}
+		}
+	}
+	return nil
+}
+
+// Check the rules that apply to each item of a slice
+func checkItemRules(name string, rules []*rule, raw string, f reflect.Value) *FieldError {
+	var itemRules []*rule
+	for _, r := range rules {
+		if r.name == "pattern" || r.name == "oneof" {
+			itemRules = append(itemRules, r)
+		}
+	}
+	return checkRules(name, itemRules, raw, f)
+}
+
+func checkValue(name string, r *rule, raw string) *FieldError {
+	switch r.name {
+	case "pattern":
+		if !r.re.MatchString(raw) {
+			return &FieldError{Field: name, Rule: r.name, Message: "is invalid"}
+		}
+	case "oneof":
+		for _, o := range r.options {
+			if o == raw {
+				return nil
+			}
+		}
+		return &FieldError{Field: name, Rule: r.name, Message: "must be one of " + strings.Join(r.options, ", ")}
+	}
+	return nil
+}
+
+// Check the min, max and len rules against a length
+func checkSize(name string, rules []*rule, size int, unit string) *FieldError {
+	for _, r := range rules {
+		n := float64(size)
+		switch {
+		case r.name == "min" && n < r.n:
+			return &FieldError{Field: name, Rule: r.name, Message: fmt.Sprintf("must have at least %v %v", r.arg, unit)}
+		case r.name == "max" && n > r.n:
+			return &FieldError{Field: name, Rule: r.name, Message: fmt.Sprintf("must have at most %v %v", r.arg, unit)}
+		case r.name == "len" && n != r.n:
+			return &FieldError{Field: name, Rule: r.name, Message: fmt.Sprintf("must have %v %v", r.arg, unit)}
+		}
+	}
+	return nil
+}
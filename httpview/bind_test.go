@@ -0,0 +1,172 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type bindItem struct {
+	Name string `form:"name" validate:"required,max=5"`
+	Qty  int    `form:"qty" validate:"min=1,max=10"`
+}
+
+type bindAddress struct {
+	City string `form:"city" validate:"required"`
+}
+
+type bindBase struct {
+	ID uint `form:"id"`
+}
+
+type bindOrder struct {
+	bindBase
+	Email    string      `form:"email" validate:"required,pattern=^[^@,]+@[^@]+$"`
+	Status   string      `form:"status" validate:"oneof=draft|sent"`
+	Code     string      `form:"code" validate:"len=3"`
+	Paid     bool        `form:"paid"`
+	Discount *float64    `form:"discount"`
+	Due      time.Time   `form:"due"`
+	Tags     []string    `form:"tags" validate:"max=2,oneof=a|b|c"`
+	Address  bindAddress `form:"address"`
+	Billing  *bindAddress
+	Items    []bindItem `form:"items" validate:"required"`
+	Page     int        `form:"page"`
+	Internal string     `form:"-"`
+	secret   string
+}
+
+func TestBind(t *testing.T) {
+	r := &Reader{Values: url.Values{
+		"id":             {"7"},
+		"email":          {"bob@example.com"},
+		"status":         {"sent"},
+		"code":           {"abc"},
+		"paid":           {"on"},
+		"discount":       {"1.5"},
+		"due":            {"2013-06-01T00:00:00Z"},
+		"tags[]":         {"a", "c"},
+		"address.city":   {"Recife"},
+		"items[1].name":  {"pen"},
+		"items[1].qty":   {"2"},
+		"items[0].name":  {"box"},
+		"items[0].qty":   {"1"},
+		"Internal":       {"x"},
+		"Billing.city":   {"Natal"},
+		"unknown.values": {"ignored"},
+	}}
+	order := &bindOrder{Page: 1}
+	if err := r.Bind(order); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	discount := 1.5
+	expected := &bindOrder{
+		bindBase: bindBase{ID: 7},
+		Email:    "bob@example.com",
+		Status:   "sent",
+		Code:     "abc",
+		Paid:     true,
+		Discount: &discount,
+		Due:      time.Date(2013, 6, 1, 0, 0, 0, 0, time.UTC),
+		Tags:     []string{"a", "c"},
+		Address:  bindAddress{City: "Recife"},
+		Billing:  &bindAddress{City: "Natal"},
+		Items:    []bindItem{{"box", 1}, {"pen", 2}},
+		Page:     1,
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("unexpected result\n%+v\n%+v", order, expected)
+	}
+
+	r = &Reader{Values: url.Values{
+		"id":            {"-1"},
+		"email":         {"bob"},
+		"status":        {"lost"},
+		"code":          {"abcd"},
+		"tags":          {"a", "d", "b"},
+		"items[0].name": {"toolong"},
+		"items[0].qty":  {"11"},
+	}}
+	err := r.Bind(&bindOrder{})
+	errs, ok := err.(BindErrors)
+	if !ok {
+		t.Fatalf("expecting BindErrors but got %v", err)
+	}
+	rules := map[string]string{
+		"id":            "type",
+		"email":         "pattern",
+		"status":        "oneof",
+		"code":          "len",
+		"tags[1]":       "oneof",
+		"tags":          "max",
+		"address.city":  "required",
+		"items[0].name": "max",
+		"items[0].qty":  "max",
+	}
+	if len(errs) != len(rules) {
+		t.Errorf("expecting %v errors but got %v", len(rules), errs)
+	}
+	for field, rule := range rules {
+		if e := errs.Get(field); e == nil || e.Rule != rule {
+			t.Errorf("%v should fail %v but got %v", field, rule, e)
+		}
+	}
+	if e := errs.Get("items[0].qty"); e.Message != "must be at most 10" {
+		t.Errorf("unexpected message %v", e.Message)
+	}
+	if e := errs.Get("items[0].name"); e.Message != "must have at most 5 characters" {
+		t.Errorf("unexpected message %v", e.Message)
+	}
+
+	err = (&Reader{Values: url.Values{}}).Bind(&bindOrder{})
+	if errs := err.(BindErrors); errs.Get("email") == nil || errs.Get("items") == nil || errs.Get("status") != nil {
+		t.Errorf("only the required fields should fail but got %v", err)
+	}
+
+	// fields that can't be bound are skipped unless they are tagged
+	model := &struct {
+		Name string `form:"name"`
+		Meta map[string]string
+	}{}
+	if err := (&Reader{Values: url.Values{"name": {"bob"}}}).Bind(model); err != nil || model.Name != "bob" {
+		t.Errorf("unexpected result %v %v", model, err)
+	}
+	if err := r.Bind(&struct {
+		Meta map[string]string `form:"meta"`
+	}{}); err == nil {
+		t.Errorf("a tagged field that can't be bound should be reported")
+	}
+
+	// required nested structs
+	type shipping struct {
+		Address bindAddress `form:"address" validate:"required"`
+	}
+	err = (&Reader{Values: url.Values{}}).Bind(&shipping{})
+	if errs, ok := err.(BindErrors); !ok || len(errs) != 1 || errs.Get("address") == nil || errs.Get("address").Rule != "required" {
+		t.Errorf("the nested struct should be required but got %v", err)
+	}
+	if err := (&Reader{Values: url.Values{"address.city": {"Recife"}}}).Bind(&shipping{}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if err := r.Bind(bindOrder{}); err == nil {
+		t.Errorf("Bind should require a pointer")
+	}
+	if err := r.Bind(&struct {
+		X string `validate:"bogus"`
+	}{}); err == nil {
+		t.Errorf("an unknown rule should be reported")
+	}
+}
@@ -0,0 +1,81 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+var bufferPool = sync.Pool{New: func() interface{} { return &bytes.Buffer{} }}
+
+// Set how many bytes are kept in memory before sending the output to the
+// client, the default (0) keeps the whole output in memory.
+//
+// The output is kept in memory so errors can be reported with a clean 500
+// instead of half a page, once the limit is reached, the output is sent
+// and errors are only logged.
+//
+// Change the BufferLimit of DefaultEngine, should be called before
+// serving any request.
+func SetBufferLimit(limit int) {
+	DefaultEngine.BufferLimit = limit
+}
+
+// Keep the output in memory until flush is called or the limit
+// is reached
+type bufferedWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	buf         *bytes.Buffer
+	limit       int
+	sent        bool
+}
+
+func newBufferedWriter(w http.ResponseWriter, contentType string, limit int) *bufferedWriter {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return &bufferedWriter{w: w, contentType: contentType, buf: buf, limit: limit}
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	if b.sent {
+		return b.w.Write(p)
+	}
+	b.buf.Write(p)
+	if b.limit > 0 && b.buf.Len() > b.limit {
+		if err := b.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Send everything to the client
+func (b *bufferedWriter) flush() error {
+	if !b.sent {
+		b.sent = true
+		if b.w.Header().Get("Content-Type") == "" {
+			b.w.Header().Set("Content-Type", b.contentType)
+		}
+	}
+	_, err := b.w.Write(b.buf.Bytes())
+	b.buf.Reset()
+	return err
+}
+
+// Return the buffer to the pool, the writer must not be used after this
+func (b *bufferedWriter) release() {
+	bufferPool.Put(b.buf)
+	b.buf = nil
+}
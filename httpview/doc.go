@@ -1,7 +1,15 @@
 // Helper methods to combine webview templates with
 // http requests objects
 //
-// Uses the gorilla context library
+// The values set for a request are kept inside the request context,
+// the Set* and Register* functions change the values in place and the
+// With* functions return a new request.
+//
+// The Set* and Register* functions require Middleware (or
+// Engine.Middleware), which gives each request a place to keep its
+// values. Without it, the first call replaces the context of the
+// request by overwriting *req, which other code holding the same
+// *http.Request will notice.
 package httpview
 
 // The MIT License (MIT)
@@ -0,0 +1,402 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"fmt"
+	"github.com/andrebq/webview"
+	"net/http"
+	"path"
+	"sync"
+	tt "text/template"
+)
+
+// Provide the templates used by an Engine, webview.Loader implements
+// this interface
+type Source interface {
+	// Return the set used to render the views
+	Set() webview.TreeSet
+	// Return the index of the set, might be nil
+	Index() webview.Index
+}
+
+type staticSource struct {
+	set   webview.TreeSet
+	index webview.Index
+}
+
+func (s *staticSource) Set() webview.TreeSet { return s.set }
+func (s *staticSource) Index() webview.Index { return s.index }
+
+// Return a Source that always return the given set and index,
+// idx might be nil
+func StaticSource(set webview.TreeSet, idx webview.Index) Source {
+	return &staticSource{set: set, index: idx}
+}
+
+// Hold the conventions used to render the views of an application
+//
+// The package level functions (Render, RenderView, GetViewName...) use
+// the engine attached to the request by Engine.Middleware or DefaultEngine
+// if the request don't have one, so many applications with different
+// conventions can run in the same process:
+//
+//	site := &httpview.Engine{Source: siteLoader}
+//	admin := &httpview.Engine{Source: adminLoader, DefaultLayout: "admin/main.html"}
+//
+//	mux.Handle("/", site.Middleware(siteHandler))
+//	mux.Handle("/admin/", admin.Middleware(adminHandler))
+//
+// The zero value is ready to use, the fields should not be changed
+// after the first request is served.
+type Engine struct {
+	// Where the templates come from, the treeset from RegisterView
+	// takes precedence over it
+	Source Source
+	// Find the view when SetViewName isn't called, if nil or if it
+	// don't return any view, DefaultView is used.
+	//
+	// See ConventionResolver
+	Resolver ViewResolver
+	// View rendered when SetViewName isn't called, the default
+	// is index/index.html
+	DefaultView string
+	// Layout used when SetLayoutName isn't called and the view
+	// don't declare one, the default is layout/main.html
+	DefaultLayout string
+	// The alias of the template executed to render a html view,
+	// it points to the root layout, the default is main
+	Root string
+	// Functions added to every template
+	Funcs tt.FuncMap
+	// Called when Render fails, the default is TemplateErrorHandler
+	ErrorHandler ErrorHandler
+	// How many bytes are kept in memory before sending the output to
+	// the client, see SetBufferLimit
+	BufferLimit int
+	// Content-Type sent for each extension (ie.: ".svg"), when the
+	// extension isn't here, webview.ContentType is used. The front matter
+	// of the view takes precedence over it
+	ContentTypes map[string]string
+	// Request headers that ask for a partial page, when one of them is
+	// present only the fragment (see SetFragment) is rendered, without
+	// the root layout. The default is HX-Request and X-PJAX
+	PartialHeaders []string
+	// Always render the whole page, ignoring PartialHeaders
+	DisablePartials bool
+	// Encode the view data as json (or xml, when the data can be
+	// encoded as xml) when the client prefers it over html, for
+	// every view. Be careful, everything passed to
+	// SetViewData is sent, use Serializers to select what is sent
+	Negotiate bool
+	// Enable the json and xml encoding for the given views, the
+	// serializer returns the value that is encoded
+	Serializers map[string]Serializer
+	// Key used to sign the flash cookie, if nil a random key is used,
+	// so the cookie is valid only for this process
+	FlashKey []byte
+	// Name of the flash cookie, the default is _flash
+	FlashCookie string
+	// Name of the field (or map key) that receives the flashes in
+	// the view data, the default is Flashes
+	FlashField string
+	// Don't add the flashes to the view data, use Flashes to read them
+	DisableFlashInjection bool
+
+	once     sync.Once
+	renderer *webview.Renderer
+}
+
+// The engine used by requests that weren't handled by Engine.Middleware
+var DefaultEngine = &Engine{}
+
+// Return the engine attached to the request or DefaultEngine
+func engineOf(req *http.Request) *Engine {
+	if e, ok := getValue(req, engineKey); ok {
+		return e.(*Engine)
+	}
+	return DefaultEngine
+}
+
+// Attach the engine to every request, and provide a empty state
+// like Middleware does, so it is also required by the Set*/Register*
+// functions
+func (e *Engine) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if stateOf(req) == nil {
+			req = withState(req, newState())
+		}
+		setValue(req, engineKey, e)
+		h.ServeHTTP(w, req)
+	})
+}
+
+// Render the view configured to that request, see the Render function
+func (e *Engine) Render(w http.ResponseWriter, req *http.Request) {
+	if engineOf(req) != e {
+		// the error handler reads the engine from the request
+		req = withValue(req, engineKey, e)
+	}
+	if target, status, ok := GetRedirect(req); ok {
+		// should return a redirect
+		e.saveFlashes(w, req)
+		http.Redirect(w, req, target, status)
+	} else {
+		// grab the name and the data
+		// from the request
+		name := e.viewName(req)
+		if _, explicit := getValue(req, viewNameKey); !explicit && webview.IsPartial(name) {
+			// the url points to a partial, which can't be a view
+			e.errorHandler().RenderError(w, req, &RenderError{Status: http.StatusNotFound, Err: fmt.Errorf("view %v not found", name)})
+			return
+		}
+
+		data, _ := getValue(req, dataKey)
+
+		// do the actual rendering
+		if err := e.RenderView(w, req, name, data); err != nil {
+			rerr, ok := err.(*RenderError)
+			if !ok {
+				rerr = &RenderError{Status: http.StatusInternalServerError, Err: err}
+			}
+			e.errorHandler().RenderError(w, req, rerr)
+		}
+	}
+}
+
+// Render the given view, see the RenderView function
+func (e *Engine) RenderView(w http.ResponseWriter, req *http.Request, name string, data interface{}) error {
+	if webview.IsPartial(name) {
+		return &RenderError{Status: http.StatusInternalServerError, Err: fmt.Errorf("%v is a partial and cannot be rendered as a view", name)}
+	}
+	contentType := e.contentType(req, name)
+	set := e.treeSet(req)
+	if set == nil {
+		return &RenderError{Status: http.StatusInternalServerError, Err: fmt.Errorf("webview treeset not found. are your sure you called RegisterView")}
+	}
+	if isHtml(contentType) {
+		if enc := e.negotiate(w, req, name, data); enc != nil {
+			if _, has := set[name]; !has {
+				return &RenderError{Status: http.StatusNotFound, Err: fmt.Errorf("view %v not found", name)}
+			}
+			return e.renderData(e.flashWriter(w, req), req, enc, name, data)
+		}
+	}
+	if !isHtml(contentType) {
+		return e.renderViewFromTreeSet(e.flashWriter(w, req), set, emptyMap, name, name, contentType, data)
+	}
+	root := e.root()
+	alias := e.aliasMap(req, name)
+	if err := e.provideDefaults(alias, req); err != nil {
+		return &RenderError{Status: http.StatusInternalServerError, Err: err}
+	}
+	for _, k := range []string{root, "contents"} {
+		if webview.IsPartial(alias[k]) {
+			return &RenderError{Status: http.StatusInternalServerError, Err: fmt.Errorf("%v is a partial and cannot be used as %v", alias[k], k)}
+		}
+	}
+	root = e.selectRoot(w, req)
+	if root == e.root() {
+		// only a whole page shows the flashes
+		data = e.injectFlashes(req, data)
+	}
+	return e.renderViewFromTreeSet(e.flashWriter(w, req), set, alias, root, alias["contents"], contentType, data)
+}
+
+func (e *Engine) templates() *webview.Renderer {
+	e.once.Do(func() {
+		e.renderer = &webview.Renderer{Funcs: e.Funcs, Contextual: true}
+	})
+	return e.renderer
+}
+
+func (e *Engine) errorHandler() ErrorHandler {
+	if e.ErrorHandler == nil {
+		return ErrorHandlerFunc(TemplateErrorHandler)
+	}
+	return e.ErrorHandler
+}
+
+func (e *Engine) root() string {
+	if e.Root == "" {
+		return "main"
+	}
+	return e.Root
+}
+
+// Return the treeset registered for the request or the one
+// from the Source, nil if none is available
+func (e *Engine) treeSet(req *http.Request) webview.TreeSet {
+	if set, ok := getValue(req, treeSetKey); ok {
+		return set.(webview.TreeSet)
+	}
+	if e.Source != nil {
+		return e.Source.Set()
+	}
+	return nil
+}
+
+// Return the index of the set returned by treeSet
+func (e *Engine) index(req *http.Request) webview.Index {
+	if idx, ok := getValue(req, indexKey); ok {
+		return idx.(webview.Index)
+	}
+	if _, ok := getValue(req, treeSetKey); !ok && e.Source != nil {
+		// the index from the source only describes its own set
+		return e.Source.Index()
+	}
+	return nil
+}
+
+// Return the front matter of the given view, from the index if one is
+// available or the one kept by the set, nil if the view don't have one
+func (e *Engine) viewMeta(req *http.Request, name string) *webview.Meta {
+	if idx := e.index(req); idx != nil {
+		if src := idx[name]; src != nil {
+			return src.Meta
+		}
+		return nil
+	}
+	return webview.MetaOf(e.treeSet(req), name)
+}
+
+func (e *Engine) viewName(req *http.Request) string {
+	if v, ok := getValue(req, viewNameKey); ok {
+		return v.(string)
+	}
+	if e.Resolver != nil {
+		if views := e.Resolver.ResolveView(req); len(views) > 0 {
+			return e.firstView(req, views)
+		}
+	}
+	if e.DefaultView == "" {
+		return "index/index.html"
+	}
+	return e.DefaultView
+}
+
+// Return the first view that exists in the treeset, if none exists
+// the first one is returned, so the 404 refers to the preferred view.
+//
+// Partials are skipped, unless the preferred view is one, in that
+// case it is returned and Render sends a 404.
+func (e *Engine) firstView(req *http.Request, views []string) string {
+	if webview.IsPartial(views[0]) {
+		return views[0]
+	}
+	if set := e.treeSet(req); set != nil {
+		for _, v := range views {
+			if _, has := set[v]; has && !webview.IsPartial(v) {
+				return v
+			}
+		}
+	}
+	return views[0]
+}
+
+func (e *Engine) layoutName(req *http.Request, view string) string {
+	if v, ok := getValue(req, layoutNameKey); ok {
+		return v.(string)
+	}
+	if meta := e.viewMeta(req, view); meta != nil && meta.Layout != "" {
+		return meta.Layout
+	}
+	if e.DefaultLayout == "" {
+		return "layout/main.html"
+	}
+	return e.DefaultLayout
+}
+
+// Return a copy of the alias of the request with the defaults for
+// "contents" (the view) and the root alias (its layout), the copy can
+// be changed freely
+func (e *Engine) aliasMap(req *http.Request, view string) map[string]string {
+	alias := GetAlias(req).Map()
+	if _, has := alias["contents"]; !has {
+		alias["contents"] = view
+	}
+	if _, has := alias[e.root()]; !has {
+		alias[e.root()] = e.layoutName(req, view)
+	}
+	return alias
+}
+
+// Return the Content-Type of the view, from its front matter, the
+// ContentTypes rules or its extension
+func (e *Engine) contentType(req *http.Request, name string) string {
+	if meta := e.viewMeta(req, name); meta != nil && meta.ContentType != "" {
+		return meta.ContentType
+	}
+	if ct, has := e.ContentTypes[path.Ext(name)]; has {
+		return ct
+	}
+	return webview.ContentType(name)
+}
+
+// Add the layout chain and the sections to the alias returned by aliasMap,
+// alias is changed in place, so it must not be shared with other requests
+func (e *Engine) provideDefaults(alias map[string]string, req *http.Request) error {
+	root := e.root()
+	chain := []string{alias["contents"], alias[root]}
+	// the layout might extend other layouts
+	layouts, err := e.layoutChain(req, alias[root])
+	if err != nil {
+		return err
+	}
+	if len(layouts) > 1 {
+		chain = append([]string{alias["contents"]}, layouts...)
+		for k, v := range webview.ChainAlias(chain) {
+			if k == "main" {
+				k = root
+			}
+			alias[k] = v
+		}
+	}
+	// sections defined by the view and its layouts
+	if set := e.treeSet(req); set != nil {
+		for k, v := range webview.SectionAlias(set, chain) {
+			if _, has := alias[k]; !has {
+				alias[k] = v
+			}
+		}
+	}
+	return nil
+}
+
+// Return the layout chain starting at layout, using the index of the set
+// if one is available or the front matter kept by the set
+func (e *Engine) layoutChain(req *http.Request, layout string) ([]string, error) {
+	if idx := e.index(req); idx != nil {
+		return webview.LayoutChain(idx, layout)
+	}
+	return webview.LayoutChainOf(e.treeSet(req), layout)
+}
+
+// Render the given template from the treeset using the given alias map,
+// returns a 404 if the view isn't on the set
+func (e *Engine) renderViewFromTreeSet(w http.ResponseWriter, set webview.TreeSet, alias map[string]string, root, view, contentType string, data interface{}) error {
+	if alias == nil {
+		alias = emptyMap
+	}
+	if _, has := set[view]; !has {
+		return &RenderError{Status: http.StatusNotFound, Err: fmt.Errorf("view %v not found", view)}
+	}
+	bw := newBufferedWriter(w, contentType, e.BufferLimit)
+	defer bw.release()
+	if err := e.templates().ExecuteTemplate(bw, set, alias, root, data); err != nil {
+		return &RenderError{Status: http.StatusInternalServerError, Err: err, Sent: bw.sent}
+	}
+	if err := bw.flush(); err != nil {
+		return &RenderError{Status: http.StatusInternalServerError, Err: err, Sent: true}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"github.com/andrebq/webview"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEngine(t *testing.T) {
+	siteSet, err := webview.LoadDir(webview.MapVFS{
+		"layout/main.html": []byte(`<html>{{ template "contents" . }}</html>`),
+		"index/index.html": []byte(`<p>site</p>`),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	adminSet, err := webview.LoadDir(webview.MapVFS{
+		"admin/layout.html": []byte(`<admin>{{ template "contents" . }}</admin>`),
+		"admin/home.html":   []byte(`<p>{{ upper "admin" }}</p>`),
+		"admin/logo.svg":    []byte(`<svg></svg>`),
+	}, template.FuncMap{"upper": strings.ToUpper}, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+
+	site := &Engine{Source: StaticSource(siteSet, nil)}
+	admin := &Engine{
+		Source:        StaticSource(adminSet, nil),
+		DefaultView:   "admin/home.html",
+		DefaultLayout: "admin/layout.html",
+		Root:          "root",
+		Funcs:         template.FuncMap{"upper": strings.ToUpper},
+		ContentTypes:  map[string]string{".svg": "image/svg+xml"},
+	}
+
+	serve := func(e *Engine, view string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		e.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if view != "" {
+				SetViewName(req, view)
+			}
+			Render(w, req)
+		})).ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve(site, ""); w.Body.String() != "<html><p>site</p></html>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if w := serve(admin, ""); w.Body.String() != "<admin><p>ADMIN</p></admin>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	w := serve(admin, "admin/logo.svg")
+	if ct := w.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("unexpected content type %v", ct)
+	}
+	if w.Body.String() != "<svg></svg>" {
+		t.Errorf("a view that isn't html should be rendered without the layout but got %q", w.Body.String())
+	}
+
+	// without the middleware, the engine still uses its own conventions
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	admin.Render(w, req)
+	if w.Body.String() != "<admin><p>ADMIN</p></admin>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if GetViewName(req) != "index/index.html" {
+		t.Errorf("requests without an engine should use DefaultEngine")
+	}
+}
@@ -0,0 +1,102 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/andrebq/webview"
+	"log"
+	"net/http"
+)
+
+// Error returned by RenderView
+type RenderError struct {
+	// The status that should be sent to the client
+	Status int
+	// The error itself
+	Err error
+	// True if part of the output was already sent
+	// to the client
+	Sent bool
+}
+
+func (r *RenderError) Error() string {
+	return fmt.Sprintf("%v: %v", r.Status, r.Err)
+}
+
+// Data used to render the error templates
+type ErrorData struct {
+	// The status code sent to the client
+	Status int
+	// The text for Status, as returned by http.StatusText
+	StatusText string
+	// The error itself, usually it shouldn't be
+	// displayed to the client
+	Err error
+}
+
+// Called by Render when RenderView fails
+type ErrorHandler interface {
+	RenderError(w http.ResponseWriter, req *http.Request, err *RenderError)
+}
+
+// Implements the ErrorHandler interface
+type ErrorHandlerFunc func(w http.ResponseWriter, req *http.Request, err *RenderError)
+
+// Call the function
+func (ef ErrorHandlerFunc) RenderError(w http.ResponseWriter, req *http.Request, err *RenderError) {
+	ef(w, req, err)
+}
+
+// Change the handler called when Render fails for DefaultEngine,
+// the default is TemplateErrorHandler.
+//
+// Should be called before serving any request.
+func SetErrorHandler(h ErrorHandler) {
+	DefaultEngine.ErrorHandler = h
+}
+
+// Return the name of the view used to render the given status, ie.: errors/404.html
+func ErrorViewName(status int) string {
+	return fmt.Sprintf("errors/%d.html", status)
+}
+
+// Render the view returned by ErrorViewName using the treeset of the request
+// engine (without any layout) with a ErrorData, if the view can't be rendered a
+// plain text message is sent instead.
+//
+// If part of the output was already sent, the error is only logged.
+func TemplateErrorHandler(w http.ResponseWriter, req *http.Request, err *RenderError) {
+	log.Printf("error rendering %v: %v", req.URL.Path, err)
+	if err.Sent {
+		return
+	}
+	w.Header().Del("Content-Type")
+	e := engineOf(req)
+	if set := e.treeSet(req); set != nil {
+		name := ErrorViewName(err.Status)
+		if _, has := set[name]; has {
+			buf := &bytes.Buffer{}
+			data := &ErrorData{Status: err.Status, StatusText: http.StatusText(err.Status), Err: err.Err}
+			if terr := e.templates().ExecuteTemplate(buf, set, emptyMap, name, data); terr == nil {
+				w.Header().Set("Content-Type", webview.ContentType(name))
+				w.WriteHeader(err.Status)
+				w.Write(buf.Bytes())
+				return
+			} else {
+				log.Printf("unable to render %v: %v", name, terr)
+			}
+		}
+	}
+	http.Error(w, http.StatusText(err.Status), err.Status)
+}
@@ -0,0 +1,274 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Kinds of flash messages, any other kind can be used
+const (
+	Notice = "notice"
+	Alert  = "alert"
+)
+
+// A message shown in the next page rendered for the client,
+// usually after a redirect
+type Flash struct {
+	// Notice, Alert or any other kind
+	Kind string `json:"k"`
+	// The message itself
+	Message string `json:"m"`
+}
+
+// The flashes of a request, shared by its copies
+type flashState struct {
+	sync.Mutex
+	// the messages from the cookie, valid if loaded is true
+	in     []Flash
+	loaded bool
+	// the messages added by this request
+	out []Flash
+	// true if the messages from the cookie were read, and how many
+	// messages from out were read
+	read      bool
+	readOut   int
+	hasCookie bool
+}
+
+// Used when Engine.FlashKey is nil, so the cookies are only valid
+// for this process
+var defaultFlashKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// Add a message that will be shown in the next page rendered for the client.
+//
+// Messages are carried from request to request in a signed cookie until
+// they are read, usually by a html page rendered by Render, which adds
+// them to the view data. Json responses, fragments (see IsPartialPage)
+// and views that aren't html don't read them.
+func AddFlash(req *http.Request, kind, message string) {
+	fs := flashesOf(req)
+	fs.Lock()
+	defer fs.Unlock()
+	fs.out = append(fs.out, Flash{Kind: kind, Message: message})
+}
+
+// Return the messages received from the previous request followed by
+// the ones added by this request, the messages are cleared once read.
+//
+// Render also injects them in the view data of html pages
+// (see Engine.FlashField).
+func Flashes(req *http.Request) []Flash {
+	return engineOf(req).flashes(req, true)
+}
+
+// Return the messages of the request, marking them as read if
+// read is true
+func (e *Engine) flashes(req *http.Request, read bool) []Flash {
+	fs := flashesOf(req)
+	fs.Lock()
+	defer fs.Unlock()
+	e.loadFlashes(req, fs)
+	if read {
+		fs.read = true
+		fs.readOut = len(fs.out)
+	}
+	ret := make([]Flash, 0, len(fs.in)+len(fs.out))
+	ret = append(ret, fs.in...)
+	return append(ret, fs.out...)
+}
+
+func (e *Engine) flashCookie() string {
+	if e.FlashCookie == "" {
+		return "_flash"
+	}
+	return e.FlashCookie
+}
+
+func (e *Engine) flashField() string {
+	if e.FlashField == "" {
+		return "Flashes"
+	}
+	return e.FlashField
+}
+
+func (e *Engine) flashKey() []byte {
+	if e.FlashKey == nil {
+		return defaultFlashKey
+	}
+	return e.FlashKey
+}
+
+// Return the flashes of the request
+func flashesOf(req *http.Request) *flashState {
+	if v, ok := getValue(req, flashKey); ok {
+		return v.(*flashState)
+	}
+	fs := &flashState{}
+	setValue(req, flashKey, fs)
+	return fs
+}
+
+// Read the messages from the cookie, only the first call reads
+// the cookie, fs must be locked
+func (e *Engine) loadFlashes(req *http.Request, fs *flashState) {
+	if fs.loaded {
+		return
+	}
+	fs.loaded = true
+	if c, err := req.Cookie(e.flashCookie()); err == nil {
+		fs.hasCookie = true
+		fs.in = e.decodeFlashes(c.Value)
+	}
+}
+
+// Write the cookie used by the next request, carrying the messages
+// that weren't read. Must be called before the response is sent.
+func (e *Engine) saveFlashes(w http.ResponseWriter, req *http.Request) {
+	fs := flashesOf(req)
+	fs.Lock()
+	defer fs.Unlock()
+	if !fs.read && len(fs.out) == 0 {
+		// the cookie, if any, is still valid
+		return
+	}
+	e.loadFlashes(req, fs)
+	var carry []Flash
+	if !fs.read {
+		carry = append(carry, fs.in...)
+	}
+	carry = append(carry, fs.out[fs.readOut:]...)
+	cookie := &http.Cookie{Name: e.flashCookie(), Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode}
+	if len(carry) > 0 {
+		cookie.Value = e.encodeFlashes(carry)
+	} else if fs.hasCookie {
+		cookie.MaxAge = -1
+	} else {
+		return
+	}
+	http.SetCookie(w, cookie)
+}
+
+// Return a writer that saves the flashes right before the response
+// is sent, so a view that fails to render don't change the cookie
+func (e *Engine) flashWriter(w http.ResponseWriter, req *http.Request) http.ResponseWriter {
+	return &flashWriter{ResponseWriter: w, save: func() { e.saveFlashes(w, req) }}
+}
+
+type flashWriter struct {
+	http.ResponseWriter
+	save  func()
+	saved bool
+}
+
+func (f *flashWriter) send() {
+	if !f.saved {
+		f.saved = true
+		f.save()
+	}
+}
+
+func (f *flashWriter) WriteHeader(status int) {
+	f.send()
+	f.ResponseWriter.WriteHeader(status)
+}
+
+func (f *flashWriter) Write(p []byte) (int, error) {
+	f.send()
+	return f.ResponseWriter.Write(p)
+}
+
+// Add the flashes to the view data of a html page, under FlashField
+//
+// If data is a map[string]interface{}, a copy with the new key is returned,
+// if it is a pointer to a struct with a []Flash field, the field is changed.
+// Other values (including nil) are returned as they are and the messages
+// stay unread.
+func (e *Engine) injectFlashes(req *http.Request, data interface{}) interface{} {
+	if e.DisableFlashInjection {
+		return data
+	}
+	flashes := e.flashes(req, false)
+	if len(flashes) == 0 {
+		return data
+	}
+	field := e.flashField()
+	if v, ok := data.(map[string]interface{}); ok {
+		m := make(map[string]interface{}, len(v)+1)
+		for k, val := range v {
+			m[k] = val
+		}
+		m[field] = flashes
+		e.flashes(req, true)
+		return m
+	}
+	rv := reflect.ValueOf(data)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Struct {
+		f := rv.Elem().FieldByName(field)
+		if f.IsValid() && f.CanSet() && f.Type() == reflect.TypeOf(flashes) {
+			f.Set(reflect.ValueOf(flashes))
+			e.flashes(req, true)
+		}
+	}
+	return data
+}
+
+// Return the messages encoded as base64(json).base64(hmac)
+func (e *Engine) encodeFlashes(flashes []Flash) string {
+	payload, _ := json.Marshal(flashes)
+	value := base64.RawURLEncoding.EncodeToString(payload)
+	return value + "." + base64.RawURLEncoding.EncodeToString(e.signFlashes(value))
+}
+
+// Return the messages from the cookie value, nil if the
+// signature don't match
+func (e *Engine) decodeFlashes(value string) []Flash {
+	idx := strings.LastIndex(value, ".")
+	if idx < 0 {
+		return nil
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(value[idx+1:])
+	if err != nil || !hmac.Equal(sig, e.signFlashes(value[:idx])) {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(value[:idx])
+	if err != nil {
+		return nil
+	}
+	var flashes []Flash
+	if json.Unmarshal(payload, &flashes) != nil {
+		return nil
+	}
+	return flashes
+}
+
+func (e *Engine) signFlashes(value string) []byte {
+	mac := hmac.New(sha256.New, e.flashKey())
+	mac.Write([]byte(e.flashCookie()))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
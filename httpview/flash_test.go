@@ -0,0 +1,170 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"github.com/andrebq/webview"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type flashPage struct {
+	Title   string
+	Flashes []Flash
+}
+
+func TestFlash(t *testing.T) {
+	set, err := webview.LoadDir(webview.MapVFS{
+		"layout/main.html": []byte(`{{ range .Flashes }}[{{ .Kind }}:{{ .Message }}]{{ end }}{{ template "contents" . }}`),
+		"index/index.html": []byte(`{{ .Title }}`),
+		"index/nil.html":   []byte(`{{ . }}`),
+		"index/fail.html":  []byte(`{{ .Title.Missing }}`),
+		"script.js":        []byte(`var title = {{ .Title }};`),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	e := &Engine{Source: StaticSource(set, nil), FlashKey: []byte("secret")}
+
+	// POST: add the messages and redirect
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/items", nil)
+	AddFlash(req, Notice, "saved")
+	AddFlash(req, "custom", "<b>")
+	RedirectLocal(req, "/items")
+	e.Render(w, req)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "_flash" || !cookies[0].HttpOnly {
+		t.Fatalf("unexpected cookies %v", cookies)
+	}
+	flash := cookies[0]
+
+	// GET: show the messages and clear them
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/items", nil)
+	req.AddCookie(flash)
+	SetViewData(req, &flashPage{Title: "items"})
+	e.Render(w, req)
+	if w.Body.String() != "[notice:saved][custom:&lt;b&gt;]items" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("the cookie should be removed but got %v", cookies)
+	}
+
+	// maps receive a copy with the messages
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/items", nil)
+	req.AddCookie(flash)
+	data := map[string]interface{}{"Title": "map"}
+	SetViewData(req, data)
+	AddFlash(req, Alert, "now")
+	e.Render(w, req)
+	if w.Body.String() != "[notice:saved][custom:&lt;b&gt;][alert:now]map" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if _, has := data["Flashes"]; has {
+		t.Errorf("the map from the handler should not be changed")
+	}
+
+	// unread messages survive another redirect, the cookie is kept
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/old", nil)
+	req.AddCookie(flash)
+	RedirectLocal(req, "/items")
+	e.Render(w, req)
+	if cookies := w.Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("the cookie should be kept but got %v", cookies)
+	}
+
+	// and new messages are added to them
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/old", nil)
+	req.AddCookie(flash)
+	AddFlash(req, Alert, "moved")
+	RedirectLocal(req, "/items")
+	e.Render(w, req)
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || len(cookies[0].Value) <= len(flash.Value) {
+		t.Errorf("the messages should be carried but got %v", cookies)
+	}
+
+	// json, fragments and views that aren't html keep the messages
+	e.Negotiate = true
+	for _, r := range []struct {
+		path   string
+		header string
+		value  string
+		view   string
+	}{
+		{"/items", "Accept", "application/json", "index/index.html"},
+		{"/items", "HX-Request", "true", "index/index.html"},
+		{"/items.js", "", "", "script.js"},
+	} {
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", r.path, nil)
+		req.AddCookie(flash)
+		if r.header != "" {
+			req.Header.Set(r.header, r.value)
+		}
+		SetViewName(req, r.view)
+		SetViewData(req, map[string]interface{}{"Title": "other"})
+		e.Render(w, req)
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "saved") {
+			t.Errorf("%v: unexpected response %v %q", r.view, w.Code, w.Body.String())
+		}
+		if cookies := w.Result().Cookies(); len(cookies) != 0 {
+			t.Errorf("%v: the cookie should be kept but got %v", r.view, cookies)
+		}
+	}
+	e.Negotiate = false
+
+	// nil data isn't replaced and the messages stay unread
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(flash)
+	SetViewName(req, "index/nil.html")
+	e.Render(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if cookies := w.Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("the cookie should be kept but got %v", cookies)
+	}
+
+	// a page that fails to render keeps the messages
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(flash)
+	SetViewName(req, "index/fail.html")
+	SetViewData(req, map[string]interface{}{"Title": "fail"})
+	e.Render(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if cookies := w.Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("the cookie should be kept but got %v", cookies)
+	}
+
+	// tampered cookies are ignored
+	req = httptest.NewRequest("GET", "/items", nil)
+	req.AddCookie(&http.Cookie{Name: "_flash", Value: "W3siayI6ImFsZXJ0IiwibSI6ImhpIn1d." + flash.Value[len(flash.Value)-10:]})
+	if f := (&Engine{FlashKey: []byte("secret")}).flashes(req, true); len(f) != 0 {
+		t.Errorf("a bad signature should be ignored but got %v", f)
+	}
+	req = httptest.NewRequest("GET", "/items", nil)
+	req.AddCookie(flash)
+	if f := (&Engine{FlashKey: []byte("other")}).flashes(req, true); len(f) != 0 {
+		t.Errorf("a cookie signed with other key should be ignored but got %v", f)
+	}
+}
@@ -0,0 +1,86 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"net/http"
+)
+
+// Headers sent by htmx and pjax when they only need part of the page
+var defaultPartialHeaders = []string{"HX-Request", "X-PJAX"}
+
+// Set the template rendered when the request asks for a partial page,
+// the default is "contents".
+//
+// The name can be any template or alias, including the sections of the
+// view, ie.: "@sidebar" (see webview.SectionName).
+func SetFragment(req *http.Request, name string) {
+	setValue(req, fragmentKey, name)
+}
+
+// Same as SetFragment but returns a new request, req isn't changed
+func WithFragment(req *http.Request, name string) *http.Request {
+	return withValue(req, fragmentKey, name)
+}
+
+// Return the template rendered when the request asks for a partial page
+func GetFragment(req *http.Request) string {
+	if v, ok := getValue(req, fragmentKey); ok {
+		return v.(string)
+	}
+	return "contents"
+}
+
+// Check if the request asks for a partial page, using the PartialHeaders
+// of the engine attached to the request
+func IsPartialPage(req *http.Request) bool {
+	return engineOf(req).isPartialPage(req)
+}
+
+func (e *Engine) partialHeaders() []string {
+	if e.DisablePartials {
+		return nil
+	}
+	if e.PartialHeaders == nil {
+		return defaultPartialHeaders
+	}
+	return e.PartialHeaders
+}
+
+func (e *Engine) isPartialPage(req *http.Request) bool {
+	for _, h := range e.partialHeaders() {
+		if v := req.Header.Get(h); v != "" && v != "false" {
+			return true
+		}
+	}
+	return false
+}
+
+// Select the template executed to render a html view, the root layout
+// or the fragment if the request asks for a partial page.
+//
+// The response varies on the partial headers, so caches don't mix the
+// full and the partial page.
+func (e *Engine) selectRoot(w http.ResponseWriter, req *http.Request) string {
+	headers := e.partialHeaders()
+	for _, h := range headers {
+		w.Header().Add("Vary", h)
+	}
+	if !e.isPartialPage(req) {
+		return e.root()
+	}
+	if req.Header.Get("X-PJAX") != "" {
+		// pjax uses it to update the browser location
+		w.Header().Set("X-PJAX-URL", req.URL.RequestURI())
+	}
+	return GetFragment(req)
+}
@@ -0,0 +1,81 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"github.com/andrebq/webview"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPartialPage(t *testing.T) {
+	set, err := webview.LoadDir(webview.MapVFS{
+		"layout/main.html": []byte(`<html>{{ template "contents" . }}</html>`),
+		"index/index.html": []byte(`{{ define "@side" }}<aside></aside>{{ end }}<p>index</p>`),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	def := &Engine{Source: StaticSource(set, nil)}
+	e := &Engine{Source: StaticSource(set, nil), PartialHeaders: []string{"X-Partial"}}
+
+	serve := func(e *Engine, header, fragment string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/users?page=2", nil)
+		if header != "" {
+			req.Header.Set(header, "true")
+		}
+		if fragment != "" {
+			SetFragment(req, fragment)
+		}
+		e.Render(w, req)
+		return w
+	}
+
+	w := serve(def, "", "")
+	if w.Body.String() != "<html><p>index</p></html>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if v := strings.Join(w.Header()["Vary"], ","); !strings.Contains(v, "HX-Request,X-PJAX") {
+		t.Errorf("unexpected vary %v", v)
+	}
+
+	w = serve(def, "HX-Request", "")
+	if w.Body.String() != "<p>index</p>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-PJAX-URL") != "" {
+		t.Errorf("X-PJAX-URL should be sent only to pjax")
+	}
+
+	w = serve(def, "X-PJAX", "@side")
+	if w.Body.String() != "<aside></aside>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if u := w.Header().Get("X-PJAX-URL"); u != "/users?page=2" {
+		t.Errorf("unexpected pjax url %v", u)
+	}
+
+	if w = serve(e, "HX-Request", ""); w.Body.String() != "<html><p>index</p></html>" {
+		t.Errorf("only the configured headers should be used but got %q", w.Body.String())
+	}
+	if w = serve(e, "X-Partial", ""); w.Body.String() != "<p>index</p>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+
+	e.DisablePartials = true
+	if w = serve(e, "X-Partial", ""); w.Body.String() != "<html><p>index</p></html>" || strings.Contains(strings.Join(w.Header()["Vary"], ","), "X-Partial") {
+		t.Errorf("partials should be disabled but got %q %v", w.Body.String(), w.Header())
+	}
+}
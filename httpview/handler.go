@@ -12,130 +12,206 @@ package httpview
 // subject to the following conditions:
 
 import (
-	"fmt"
 	"github.com/andrebq/webview"
-	"github.com/gorilla/context"
+	"mime"
 	"net/http"
-	"net/url"
 )
 
-var (
-	emptyMap = map[string]string{}
-)
+var emptyMap = map[string]string{}
 
 // Set the name of the view that should be rendered
 // at Render
 func SetViewName(req *http.Request, name string) {
-	context.Set(req, viewNameKey, name)
+	setValue(req, viewNameKey, name)
+}
+
+// Same as SetViewName but returns a new request, req isn't changed
+func WithViewName(req *http.Request, name string) *http.Request {
+	return withValue(req, viewNameKey, name)
 }
 
-// Return the name of the View from the request
+// Return the name of the View from the request, if nothing was set,
+// returns the view found by the Resolver of the engine or its
+// DefaultView (index/index.html)
 func GetViewName(req *http.Request) string {
-	if v, ok := context.GetOk(req, viewNameKey); !ok {
-		return "index/index.html"
-	} else {
-		return v.(string)
-	}
+	return engineOf(req).viewName(req)
 }
 
 // Set the layout that will be used.
 //
-// To render the view the layout should call {{ template "contents" }}
+// To render the view the layout should call {{ template "contents" }},
+// sections defined by the view are rendered with {{ yield "name" . }}
+// (see webview.SectionName)
 func SetLayoutName(req *http.Request, name string) {
-	context.Set(req, layoutNameKey, name)
+	setValue(req, layoutNameKey, name)
+}
+
+// Same as SetLayoutName but returns a new request, req isn't changed
+func WithLayoutName(req *http.Request, name string) *http.Request {
+	return withValue(req, layoutNameKey, name)
 }
 
-// Return the layout defined, if nothing was set, returns
-// layout/main.html
+// Return the layout defined, if nothing was set, returns the layout
+// declared in the front matter of the view or the DefaultLayout of the
+// engine (layout/main.html)
 func GetLayoutName(req *http.Request) string {
-	if v, ok := context.GetOk(req, layoutNameKey); !ok {
-		return "layout/main.html"
-	} else {
-		return v.(string)
-	}
+	e := engineOf(req)
+	return e.layoutName(req, e.viewName(req))
 }
 
 // Set the data that should be used to render the
 // template
 func SetViewData(req *http.Request, data interface{}) {
-	context.Set(req, dataKey, data)
+	setValue(req, dataKey, data)
+}
+
+// Same as SetViewData but returns a new request, req isn't changed
+func WithViewData(req *http.Request, data interface{}) *http.Request {
+	return withValue(req, dataKey, data)
 }
 
 // Render the view configured to that request
 //
-// This method will redirect if any of the Redirect* methods were called
-// or will try to render the view configured with SetView{Name/Data}
+// This method will redirect, with the stored status, if any of the
+// Redirect* methods were called or will try to render the view
+// configured with SetView{Name/Data}
+//
+// The messages from AddFlash are carried to the next request until
+// a html page is rendered, which receives them in its data (see AddFlash).
+//
+// If the view can't be rendered, the ErrorHandler of the engine is called
+// (see SetErrorHandler).
+//
+// Uses the engine attached to the request or DefaultEngine
 func Render(w http.ResponseWriter, req *http.Request) {
-	if redirect, ok := context.GetOk(req, redirectInfoKey); ok {
-		// should return a redirect
-		http.Redirect(w, req, redirect.(*url.URL).String(), http.StatusFound)
-	} else {
-		// grab the name and the data
-		// from the request
-		name := GetViewName(req)
-
-		data, _ := context.GetOk(req, dataKey)
-
-		// do the actual rendering
-		RenderView(w, req, name, data)
-	}
+	engineOf(req).Render(w, req)
 }
 
 // Render the given view using the alias and treeset registered for the current request
 //
-// Use the RegisterView in your http pipeline beforer calling RenderView
+// Use the RegisterView in your http pipeline beforer calling RenderView,
+// or set the Source of the engine.
+//
+// The view is rendered as "contents", unless the alias of the request
+// says otherwise, inside the layout from SetLayoutName, its front matter
+// or the DefaultLayout of the engine.
+//
+// The Content-Type is selected from the view front matter, the ContentTypes
+// of the engine or the view extension. Views that aren't html (ie.: .js,
+// .css and .json) are rendered without the layout, escaped using the rules
+// for their extension.
+//
+// When the view has a serializer (see Engine.Serializers) or the engine
+// has Negotiate enabled, and the Accept header prefers json or xml over
+// html, the data is encoded instead of rendering the html view.
+//
+// When the request asks for a partial page (see IsPartialPage), only
+// the fragment from SetFragment is rendered, by default "contents", which
+// is the view (or the layout below the root one).
+//
+// The output is sent only after the view is rendered (see SetBufferLimit),
+// errors are returned as a *RenderError.
 func RenderView(w http.ResponseWriter, req *http.Request, name string, data interface{}) error {
-	if tree, ok := context.GetOk(req, treeSetKey); ok {
-		alias := GetAliasMap(req)
-		provideDefaults(alias, req)
-		return renderViewFromTreeSet(w, req, tree.(webview.TreeSet), alias, "main", data)
-	} else {
-		return fmt.Errorf("webview treeset not found. are your sure you called RegisterView")
-	}
+	return engineOf(req).RenderView(w, req, name, data)
 }
 
-func provideDefaults(alias map[string]string, req *http.Request) {
-	if _, has := alias["main"]; !has {
-		alias["main"] = GetLayoutName(req)
-	}
-	if _, has := alias["contents"]; !has {
-		alias["contents"] = GetViewName(req)
-	}
+// Check if a view with the given Content-Type should be rendered as
+// a html page, inside the layout
+func isHtml(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "text/html" || mediaType == "application/xhtml+xml")
 }
 
 // Set the alias that will be used to render the template
+//
+// The map is copied, so it can be shared by many requests and changing
+// it after this call has no effect.
 func SetAliasMap(req *http.Request, alias map[string]string) {
-	context.Set(req, aliasMapKey, alias)
+	SetAlias(req, webview.NewAlias(alias))
 }
 
-// Get the alias that will be used to render the template
+// Same as SetAliasMap but returns a new request, req isn't changed
+func WithAliasMap(req *http.Request, alias map[string]string) *http.Request {
+	return WithAlias(req, webview.NewAlias(alias))
+}
+
+// Get the alias that will be used to render the template, including
+// "contents" and the root alias of the engine ("main").
+//
+// The map belongs to the request: it is a copy of the alias set with
+// SetAliasMap or SetAlias (which is never changed), so changes to it are
+// used by Render without affecting other requests.
 func GetAliasMap(req *http.Request) map[string]string {
-	if v, ok := context.GetOk(req, aliasMapKey); !ok {
-		alias := map[string]string{
-			"contents": GetViewName(req),
-			"main":     GetLayoutName(req),
+	if v, ok := getValue(req, aliasMapKey); ok {
+		if m, ok := v.(requestAlias); ok {
+			return m
 		}
-		SetAliasMap(req, alias)
-		return alias
-	} else {
-		return v.(map[string]string)
 	}
+	e := engineOf(req)
+	m := requestAlias(e.aliasMap(req, e.viewName(req)))
+	setValue(req, aliasMapKey, m)
+	return m
 }
 
-// Register the treeset and the alias name for the current request
-func RegisterView(req *http.Request, set webview.TreeSet) {
-	context.Set(req, treeSetKey, set)
+// Set the alias that will be used to render the template, to change
+// only some entries, compose it with the current one:
+//
+//	SetAlias(req, GetAlias(req).Set("@head", "users/head.html"))
+func SetAlias(req *http.Request, alias webview.Alias) {
+	setValue(req, aliasMapKey, alias)
 }
 
-// Render the given template from the treeset using the given alias map
-func renderViewFromTreeSet(w http.ResponseWriter, req *http.Request, set webview.TreeSet, alias map[string]string, view string, data interface{}) error {
-	if alias == nil {
-		alias = emptyMap
+// Same as SetAlias but returns a new request, req isn't changed
+func WithAlias(req *http.Request, alias webview.Alias) *http.Request {
+	return withValue(req, aliasMapKey, alias)
+}
+
+// Return the alias set for the request, without the defaults
+// provided by the engine
+func GetAlias(req *http.Request) webview.Alias {
+	v, _ := getValue(req, aliasMapKey)
+	switch v := v.(type) {
+	case webview.Alias:
+		return v
+	case requestAlias:
+		return webview.NewAlias(v)
 	}
-	tmpl, err := webview.Template(set, alias)
-	if err != nil {
-		return err
+	return webview.Alias{}
+}
+
+// The map returned by GetAliasMap, owned by a single request
+type requestAlias map[string]string
+
+// Copy the map, so requests created by the With* functions don't
+// share it
+func (r requestAlias) clone() interface{} {
+	c := make(requestAlias, len(r))
+	for k, v := range r {
+		c[k] = v
 	}
-	err = tmpl.ExecuteTemplate(w, view, data)
-	return err
+	return c
+}
+
+// Register the treeset and the alias name for the current request
+//
+// Templates are cached between requests, so the set must not be
+// changed after it is registered, create a new one instead (like webview.Loader does).
+func RegisterView(req *http.Request, set webview.TreeSet) {
+	setValue(req, treeSetKey, set)
+}
+
+// Same as RegisterView but returns a new request, req isn't changed
+func WithTreeSet(req *http.Request, set webview.TreeSet) *http.Request {
+	return withValue(req, treeSetKey, set)
+}
+
+// Register the index of the treeset, used to read the front matter
+// of the views instead of the one kept by the set (see webview.MetaOf)
+func RegisterIndex(req *http.Request, idx webview.Index) {
+	setValue(req, indexKey, idx)
+}
+
+// Same as RegisterIndex but returns a new request, req isn't changed
+func WithIndex(req *http.Request, idx webview.Index) *http.Request {
+	return withValue(req, indexKey, idx)
 }
@@ -0,0 +1,228 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"github.com/andrebq/webview"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func loadTestSet(t *testing.T) webview.TreeSet {
+	set, err := webview.LoadDir(webview.MapVFS{
+		"layout/main.html": []byte(`<html>{{ template "contents" . }}</html>`),
+		"index/index.html": []byte(`<p>{{ . }}</p>`),
+		"index/fail.html":  []byte(`<p>before</p>{{ .Missing }}`),
+		"errors/500.html":  []byte(`<h1>{{ .Status }} {{ .StatusText }}</h1>`),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	return set
+}
+
+func render(set webview.TreeSet, view string, data interface{}) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	RegisterView(req, set)
+	SetViewName(req, view)
+	SetViewData(req, data)
+	Render(w, req)
+	return w
+}
+
+func TestRender(t *testing.T) {
+	w := render(loadTestSet(t), "index/index.html", "<hi>")
+	if w.Code != http.StatusOK || w.Body.String() != "<html><p>&lt;hi&gt;</p></html>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("unexpected content type %v", ct)
+	}
+}
+
+func TestRenderView(t *testing.T) {
+	set, err := webview.LoadDir(webview.MapVFS{
+		"layout/main.html":  []byte(`<html>{{ template "contents" . }}</html>`),
+		"layout/admin.html": []byte(`<admin>{{ template "contents" . }}</admin>`),
+		"index/index.html":  []byte(`index`),
+		"users/edit.html":   []byte("---\nlayout: layout/admin.html\n---\nedit"),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	for view, expected := range map[string]struct {
+		code int
+		body string
+	}{
+		"users/edit.html":    {http.StatusOK, "<admin>edit</admin>"},
+		"users/missing.html": {http.StatusNotFound, ""},
+	} {
+		req, _ := http.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		RegisterView(req, set)
+		err := RenderView(w, req, view, nil)
+		code := http.StatusOK
+		if rerr, ok := err.(*RenderError); ok {
+			code = rerr.Status
+		}
+		if code != expected.code || w.Body.String() != expected.body {
+			t.Errorf("%v: unexpected response %v %q %v", view, code, w.Body.String(), err)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	set := loadTestSet(t)
+
+	w := render(set, "index/fail.html", 1)
+	if w.Code != http.StatusInternalServerError || w.Body.String() != "<h1>500 Internal Server Error</h1>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+
+	w = render(set, "index/missing.html", nil)
+	if w.Code != http.StatusNotFound || w.Body.String() != "Not Found\n" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+
+	var handled *RenderError
+	SetErrorHandler(ErrorHandlerFunc(func(w http.ResponseWriter, req *http.Request, err *RenderError) {
+		handled = err
+	}))
+	SetBufferLimit(4)
+	defer func() {
+		SetErrorHandler(ErrorHandlerFunc(TemplateErrorHandler))
+		SetBufferLimit(0)
+	}()
+	w = render(set, "index/fail.html", 1)
+	if handled == nil || !handled.Sent {
+		t.Fatalf("the error handler should be called after the output is sent but got %v", handled)
+	}
+	if w.Code != http.StatusOK || w.Body.String() != "<html><p>before</p>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+}
+
+func TestSharedAliasMap(t *testing.T) {
+	set, err := webview.LoadDir(webview.MapVFS{
+		"layout/main.html":  []byte(`<html>{{ template "contents" . }}</html>`),
+		"layout/admin.html": []byte(`<admin>{{ template "contents" . }}</admin>`),
+		"index/index.html":  []byte(`{{ define "@side" }}side{{ end }}<p>index</p>`),
+		"users/list.html":   []byte(`<p>users</p>`),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	shared := map[string]string{"main": "layout/admin.html"}
+
+	done := make(chan string)
+	for _, view := range []string{"index/index.html", "users/list.html"} {
+		go func(view string) {
+			for i := 0; i < 50; i++ {
+				req, _ := http.NewRequest("GET", "/", nil)
+				w := httptest.NewRecorder()
+				RegisterView(req, set)
+				SetAliasMap(req, shared)
+				SetViewName(req, view)
+				Render(w, req)
+				if i == 0 {
+					done <- w.Body.String()
+				}
+			}
+			done <- ""
+		}(view)
+	}
+	for i := 0; i < 4; i++ {
+		if body := <-done; body != "" && body != "<admin><p>index</p></admin>" && body != "<admin><p>users</p></admin>" {
+			t.Errorf("unexpected response %q", body)
+		}
+	}
+	if len(shared) != 1 || shared["main"] != "layout/admin.html" {
+		t.Errorf("the shared alias map should not be changed but got %v", shared)
+	}
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	SetAliasMap(req, shared)
+	GetAliasMap(req)["@side"] = "index/index.html"
+	if m := GetAlias(req).Map(); len(m) != 3 || m["main"] != "layout/admin.html" || m["@side"] != "index/index.html" {
+		t.Errorf("changes to GetAliasMap should be kept by the request but got %v", m)
+	}
+	if len(shared) != 1 {
+		t.Errorf("the shared alias map should not be changed but got %v", shared)
+	}
+	copied := WithViewData(req, nil)
+	GetAliasMap(copied)["@side"] = "users/list.html"
+	if GetAliasMap(req)["@side"] != "index/index.html" {
+		t.Errorf("the copy of the request should not share the alias map")
+	}
+	SetAlias(req, GetAlias(req).Set("@side", "x.html"))
+	if m := GetAlias(req).Map(); m["@side"] != "x.html" {
+		t.Errorf("unexpected alias %v", m)
+	}
+
+	// the baseline pattern: change the map returned by GetAliasMap
+	req, _ = http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	RegisterView(req, set)
+	GetAliasMap(req)["main"] = "layout/admin.html"
+	Render(w, req)
+	if w.Body.String() != "<admin><p>index</p></admin>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+}
+
+func TestNestedLayoutWithoutIndex(t *testing.T) {
+	set, err := webview.LoadDir(webview.MapVFS{
+		"layout/main.html": []byte(`<html>{{ template "contents" . }}</html>`),
+		"admin/main.html":  []byte("---\nlayout: layout/main.html\n---\n<div>{{ template \"contents\" . }}</div>"),
+		"index/index.html": []byte(`index`),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	req, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	RegisterView(req, set)
+	SetLayoutName(req, "admin/main.html")
+	Render(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "<html><div>index</div></html>" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+}
+
+func TestFrontMatterWithoutIndex(t *testing.T) {
+	set, err := webview.LoadDir(webview.MapVFS{
+		"a.html":           []byte(`<a>{{ template "contents" . }}</a>`),
+		"users/index.html": []byte("---\nlayout: a.html\n---\nusers"),
+		"users/feed.html":  []byte("---\ncontent-type: application/atom+xml\n---\n<feed></feed>"),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	for view, expected := range map[string]string{
+		"users/index.html": "<a>users</a>",
+		"users/feed.html":  "<feed></feed>",
+	} {
+		req, _ := http.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		RegisterView(req, set)
+		SetViewName(req, view)
+		Render(w, req)
+		if w.Code != http.StatusOK || w.Body.String() != expected {
+			t.Errorf("%v: unexpected response %v %q", view, w.Code, w.Body.String())
+		}
+		if view == "users/feed.html" && w.Header().Get("Content-Type") != "application/atom+xml" {
+			t.Errorf("unexpected content type %v", w.Header().Get("Content-Type"))
+		}
+	}
+}
@@ -22,4 +22,12 @@ const (
 	viewNameKey     = key(3)
 	dataKey         = key(4)
 	layoutNameKey   = key(5)
+	indexKey        = key(6)
+	engineKey       = key(7)
+	actionKey       = key(8)
+	fragmentKey     = key(9)
+	flashKey        = key(10)
+	// the key used to store the state inside the
+	// request context
+	stateKey = key(255)
 )
@@ -0,0 +1,181 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Change the shape of the view data before it is encoded as json or xml,
+// ie.: to hide fields that only the template needs
+type Serializer func(req *http.Request, data interface{}) (interface{}, error)
+
+// Encode the view data with the given media type
+type dataEncoder struct {
+	contentType string
+	encode      func(w io.Writer, v interface{}) error
+}
+
+var (
+	// the media types offered for a html view, html comes first
+	// so it wins the ties. xml is offered only when the data can
+	// be encoded by encoding/xml (see xmlEncodable)
+	offers    = []string{"text/html", "application/json"}
+	xmlOffers = []string{"text/html", "application/json", "application/xml", "text/xml"}
+	encoders  = map[string]*dataEncoder{
+		"application/json": {"application/json; charset=utf-8", encodeJSON},
+		"application/xml":  {"application/xml; charset=utf-8", encodeXML},
+		"text/xml":         {"text/xml; charset=utf-8", encodeXML},
+	}
+)
+
+func encodeJSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func encodeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// Return the encoder selected by the Accept header of the request,
+// nil if the template should be rendered.
+//
+// Only views with a Serializer are negotiated, unless Negotiate is true,
+// and the data is encoded only if the client prefers json or xml over
+// html, so browsers (and */*) still get the html page.
+//
+// Xml is offered for views with a Serializer or when the data can be
+// encoded as xml.
+func (e *Engine) negotiate(w http.ResponseWriter, req *http.Request, name string, data interface{}) *dataEncoder {
+	available := offers
+	if _, has := e.Serializers[name]; has {
+		available = xmlOffers
+	} else if !e.Negotiate {
+		return nil
+	} else if xmlEncodable(data) {
+		available = xmlOffers
+	}
+	w.Header().Add("Vary", "Accept")
+	return encoders[negotiate(req.Header.Get("Accept"), available)]
+}
+
+// Check if encoding/xml can encode the value, pointers and slices
+// are followed until the type of the elements is found. Maps,
+// interfaces and nil values can't be encoded.
+func xmlEncodable(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Map, reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return false
+	}
+	return true
+}
+
+// Encode the data with the given encoder, using the serializer
+// of the view if the engine has one
+func (e *Engine) renderData(w http.ResponseWriter, req *http.Request, enc *dataEncoder, name string, data interface{}) error {
+	if s := e.Serializers[name]; s != nil {
+		var err error
+		if data, err = s(req, data); err != nil {
+			return &RenderError{Status: http.StatusInternalServerError, Err: err}
+		}
+	}
+	bw := newBufferedWriter(w, enc.contentType, e.BufferLimit)
+	defer bw.release()
+	if err := enc.encode(bw, data); err != nil {
+		return &RenderError{Status: http.StatusInternalServerError, Err: err, Sent: bw.sent}
+	}
+	if err := bw.flush(); err != nil {
+		return &RenderError{Status: http.StatusInternalServerError, Err: err, Sent: true}
+	}
+	return nil
+}
+
+// Return the offer with the highest quality from the accept header,
+// ties are won by the first offer. Returns the first offer if the
+// header is empty.
+func negotiate(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	ranges := parseAccept(accept)
+	best, bestQ := offers[0], 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mr := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+		valid := mr.mediaType != ""
+		for _, p := range params[1:] {
+			kv := strings.SplitN(p, "=", 2)
+			if len(kv) == 2 && strings.ToLower(strings.TrimSpace(kv[0])) == "q" {
+				q, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+				valid = valid && err == nil
+				mr.q = q
+			}
+		}
+		if valid {
+			ranges = append(ranges, mr)
+		}
+	}
+	return ranges
+}
+
+// Return the quality of the most specific range that matches
+// the media type
+func acceptQuality(ranges []mediaRange, mediaType string) float64 {
+	major := mediaType[:strings.Index(mediaType, "/")]
+	q, specificity := 0.0, 0
+	for _, r := range ranges {
+		s := 0
+		switch r.mediaType {
+		case mediaType:
+			s = 3
+		case major + "/*":
+			s = 2
+		case "*/*":
+			s = 1
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
@@ -0,0 +1,131 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                                 "text/html",
+		"*/*":                              "text/html",
+		"application/json":                 "application/json",
+		"application/json, */*":            "text/html",
+		"application/json, */*;q=0.01":     "application/json",
+		"text/html;q=0.5, application/xml": "application/xml",
+		"text/*, application/json;q=0.9":   "text/html",
+		"application/*":                    "application/json",
+		"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8": "text/html",
+		"application/json;q=bad, text/xml":                                "text/xml",
+	}
+	for accept, expected := range cases {
+		if got := negotiate(accept, xmlOffers); got != expected {
+			t.Errorf("%q should select %v but got %v", accept, expected, got)
+		}
+	}
+}
+
+type apiUser struct {
+	Name     string
+	Password string `json:"-"`
+}
+
+func TestXmlEncodable(t *testing.T) {
+	var nilUser *apiUser
+	cases := map[string]struct {
+		value    interface{}
+		expected bool
+	}{
+		"struct":      {apiUser{}, true},
+		"pointer":     {&apiUser{}, true},
+		"nil pointer": {nilUser, true},
+		"slice":       {[]*apiUser{}, true},
+		"string":      {"bob", true},
+		"nil":         {nil, false},
+		"map":         {map[string]string{}, false},
+		"maps":        {[]map[string]string{}, false},
+		"interfaces":  {[]interface{}{}, false},
+	}
+	for name, c := range cases {
+		if got := xmlEncodable(c.value); got != c.expected {
+			t.Errorf("%v: expecting %v but got %v", name, c.expected, got)
+		}
+	}
+}
+
+func TestRenderData(t *testing.T) {
+	e := &Engine{Source: StaticSource(loadTestSet(t), nil)}
+	serve := func(view, accept string, data interface{}) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", accept)
+		SetViewName(req, view)
+		SetViewData(req, data)
+		e.Render(w, req)
+		return w
+	}
+	user := &apiUser{Name: "<bob>", Password: "secret"}
+	page := "<html><p>{&lt;bob&gt; secret}</p></html>"
+
+	// negotiation is opt-in
+	w := serve("index/index.html", "application/json", user)
+	if w.Body.String() != page || strings.Contains(strings.Join(w.Header()["Vary"], ","), "Accept") {
+		t.Errorf("the data should not be encoded by default but got %v %q", w.Code, w.Body.String())
+	}
+
+	e.Negotiate = true
+	w = serve("index/index.html", "application/json", user)
+	if w.Body.String() != `{"Name":"\u003cbob\u003e"}`+"\n" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("unexpected content type %v", ct)
+	}
+	if !strings.Contains(strings.Join(w.Header()["Vary"], ","), "Accept") {
+		t.Errorf("the response should vary on Accept")
+	}
+	if w = serve("index/index.html", "text/html", user); w.Body.String() != page {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if w = serve("nope/nothing.html", "application/json", user); w.Code != http.StatusNotFound {
+		t.Errorf("a missing view should be a 404 but got %v %q", w.Code, w.Body.String())
+	}
+	// structs can be encoded as xml, maps can't
+	w = serve("index/index.html", "application/xml", user)
+	if w.Body.String() != `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<apiUser><Name>&lt;bob&gt;</Name><Password>secret</Password></apiUser>` {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if w = serve("index/index.html", "application/xml", map[string]interface{}{"Name": "bob"}); w.Code != http.StatusOK || w.Body.String() != "<html><p>map[Name:bob]</p></html>" {
+		t.Errorf("xml with a map should render the html but got %v %q", w.Code, w.Body.String())
+	}
+
+	e.Negotiate = false
+	e.Serializers = map[string]Serializer{
+		"index/index.html": func(req *http.Request, data interface{}) (interface{}, error) {
+			return &apiUser{Name: data.(*apiUser).Name}, nil
+		},
+	}
+	if w = serve("index/index.html", "application/json", user); w.Body.String() != `{"Name":"\u003cbob\u003e"}`+"\n" {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	w = serve("index/index.html", "application/xml", user)
+	if w.Body.String() != `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<apiUser><Name>&lt;bob&gt;</Name><Password></Password></apiUser>` {
+		t.Errorf("unexpected response %v %q", w.Code, w.Body.String())
+	}
+	if w = serve("index/fail.html", "application/json", user); w.Body.String() != "<h1>500 Internal Server Error</h1>" {
+		t.Errorf("views without a serializer should not be encoded but got %v %q", w.Code, w.Body.String())
+	}
+}
@@ -1,14 +1,159 @@
 package httpview
 
 import (
-	"github.com/gorilla/context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
+// Returned when the target of a redirect points to another host
+var ErrOpenRedirect = errors.New("httpview: redirect to a foreign host")
+
+// Describe a redirect, used by SetRedirect
+type Redirect struct {
+	// The status sent to the client: 301, 302, 303, 307 or 308,
+	// the default is 302
+	Status int
+	// Where the client is sent, usually a local path with an optional
+	// query and fragment (ie.: /items?page=2). Absolute urls are accepted
+	// only if they point to the host of the request
+	Target string
+	// Keep the query from the current request
+	KeepQuery bool
+	// Parameters set on the query, they replace the ones with the same
+	// name from Target and from the current request
+	Query url.Values
+}
+
+// The redirect stored for a request
+type redirectInfo struct {
+	url    *url.URL
+	status int
+}
+
 // Set the redirect information for the given request
+//
+// The client is sent to path with a 302, the query and fragment are
+// removed, use SetRedirect to keep them.
 func RedirectLocal(req *http.Request, path string) {
-	context.Set(req, redirectInfoKey, makeRedirectFor(req, &url.URL{Path: path}))
+	setValue(req, redirectInfoKey, &redirectInfo{makeRedirectFor(req, &url.URL{Path: path}), http.StatusFound})
+}
+
+// Same as RedirectLocal but returns a new request, req isn't changed
+func WithRedirectLocal(req *http.Request, path string) *http.Request {
+	return withValue(req, redirectInfoKey, &redirectInfo{makeRedirectFor(req, &url.URL{Path: path}), http.StatusFound})
+}
+
+// Set the redirect information for the given request, an error is
+// returned if the status isn't a redirect or if the target points to
+// another host (ErrOpenRedirect). In that case, the request isn't changed.
+func SetRedirect(req *http.Request, r Redirect) error {
+	info, err := r.resolve(req)
+	if err != nil {
+		return err
+	}
+	setValue(req, redirectInfoKey, info)
+	return nil
+}
+
+// Same as SetRedirect but returns a new request, req isn't changed
+func WithRedirect(req *http.Request, r Redirect) (*http.Request, error) {
+	info, err := r.resolve(req)
+	if err != nil {
+		return nil, err
+	}
+	return withValue(req, redirectInfoKey, info), nil
+}
+
+// Redirect to target with the given status, keeping the query from
+// target. See SetRedirect
+func RedirectTo(req *http.Request, status int, target string) error {
+	return SetRedirect(req, Redirect{Status: status, Target: target})
+}
+
+// Redirect to the page from the Referer header, if the header is missing
+// or points to another host, the client is sent to fallback.
+func RedirectBack(req *http.Request, status int, fallback string) error {
+	target := fallback
+	if ref, err := url.Parse(req.Referer()); err == nil && ref.IsAbs() && sameHost(req, ref) {
+		target = ref.RequestURI()
+	}
+	return SetRedirect(req, Redirect{Status: status, Target: target})
+}
+
+// Return the url and status of the redirect set for the request,
+// ok is false if no redirect was set
+func GetRedirect(req *http.Request) (target string, status int, ok bool) {
+	if v, has := getValue(req, redirectInfoKey); has {
+		info := v.(*redirectInfo)
+		return info.url.String(), info.status, true
+	}
+	return "", 0, false
+}
+
+// Check if the status can be used in a redirect
+func isRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+func (r Redirect) resolve(req *http.Request) (*redirectInfo, error) {
+	status := r.Status
+	if status == 0 {
+		status = http.StatusFound
+	}
+	if !isRedirectStatus(status) {
+		return nil, fmt.Errorf("httpview: %v isn't a redirect status", status)
+	}
+	// browsers read \ as /, so /\host is a foreign host
+	if strings.Contains(strings.SplitN(r.Target, "?", 2)[0], `\`) {
+		return nil, ErrOpenRedirect
+	}
+	ref, err := url.Parse(r.Target)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Scheme != "" || ref.Host != "" || ref.Opaque != "" {
+		if ref.Opaque != "" || !sameHost(req, ref) {
+			return nil, ErrOpenRedirect
+		}
+	}
+	target := req.URL.ResolveReference(ref)
+	target.User = nil
+	if r.KeepQuery || len(r.Query) > 0 {
+		query := url.Values{}
+		if r.KeepQuery {
+			for k, v := range req.URL.Query() {
+				query[k] = v
+			}
+		}
+		for k, v := range ref.Query() {
+			query[k] = v
+		}
+		for k, v := range r.Query {
+			query[k] = v
+		}
+		target.RawQuery = query.Encode()
+	}
+	return &redirectInfo{url: target, status: status}, nil
+}
+
+// Check if u is a http(s) url pointing to the host of the request
+func sameHost(req *http.Request, u *url.URL) bool {
+	if u.Host == "" || (u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	return strings.EqualFold(u.Host, host)
 }
 
 // Return a URL from the given hos
@@ -0,0 +1,84 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestRedirect(t *testing.T) {
+	newReq := func() *http.Request {
+		return httptest.NewRequest("POST", "http://example.com/items/save?page=2&sort=name", nil)
+	}
+
+	req := newReq()
+	RedirectLocal(req, "/items")
+	w := httptest.NewRecorder()
+	Render(w, req)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "http://example.com/items" {
+		t.Errorf("unexpected redirect %v %v", w.Code, w.Header().Get("Location"))
+	}
+
+	req = newReq()
+	if err := RedirectTo(req, http.StatusSeeOther, "/items?page=3#top"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	w = httptest.NewRecorder()
+	Render(w, req)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "http://example.com/items?page=3#top" {
+		t.Errorf("unexpected redirect %v %v", w.Code, w.Header().Get("Location"))
+	}
+
+	req = newReq()
+	err := SetRedirect(req, Redirect{Target: "/items", KeepQuery: true, Query: url.Values{"page": {"1"}}})
+	if target, status, _ := GetRedirect(req); err != nil || status != http.StatusFound || target != "http://example.com/items?page=1&sort=name" {
+		t.Errorf("unexpected redirect %v %v %v", target, status, err)
+	}
+
+	for _, target := range []string{"//evil.com/x", "http://evil.com", `/\evil.com`, "javascript:alert(1)", "ftp://example.com/x"} {
+		req = newReq()
+		if err := RedirectTo(req, http.StatusFound, target); err != ErrOpenRedirect {
+			t.Errorf("%v should be refused but got %v", target, err)
+		}
+		if _, _, ok := GetRedirect(req); ok {
+			t.Errorf("a refused redirect should not be stored")
+		}
+	}
+	if err := RedirectTo(newReq(), http.StatusOK, "/"); err == nil {
+		t.Errorf("200 isn't a redirect status")
+	}
+	if err := RedirectTo(newReq(), http.StatusMovedPermanently, "http://EXAMPLE.com/x"); err != nil {
+		t.Errorf("a url to the same host should be accepted but got %v", err)
+	}
+
+	back := func(referer string) string {
+		req := newReq()
+		req.Header.Set("Referer", referer)
+		if err := RedirectBack(req, http.StatusSeeOther, "/home"); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		target, _, _ := GetRedirect(req)
+		return target
+	}
+	if target := back("http://example.com/items?page=2"); target != "http://example.com/items?page=2" {
+		t.Errorf("unexpected target %v", target)
+	}
+	if target := back("http://evil.com/items"); target != "http://example.com/home" {
+		t.Errorf("a foreign referer should use the fallback but got %v", target)
+	}
+	if target := back(""); target != "http://example.com/home" {
+		t.Errorf("a missing referer should use the fallback but got %v", target)
+	}
+}
@@ -0,0 +1,94 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Find the view of a request when SetViewName wasn't called
+type ViewResolver interface {
+	// Return the candidate views, the first one that exists
+	// in the treeset is rendered
+	ResolveView(req *http.Request) []string
+}
+
+// Implements the ViewResolver interface
+type ViewResolverFunc func(req *http.Request) []string
+
+// Call the function
+func (vf ViewResolverFunc) ResolveView(req *http.Request) []string {
+	return vf(req)
+}
+
+// Resolve the view from the action set with SetAction or from the
+// request path when no action was set.
+//
+// See ActionViews and PathViews
+var ConventionResolver = ViewResolverFunc(func(req *http.Request) []string {
+	if controller, action, ok := GetAction(req); ok {
+		return ActionViews(controller, action)
+	}
+	return PathViews(req.URL.Path)
+})
+
+// Return the views for the given controller and action,
+// ie.: users/edit.html then users/index.html
+func ActionViews(controller, action string) []string {
+	index := path.Join(controller, "index.html")
+	if action == "" || action == "index" {
+		return []string{index}
+	}
+	return []string{path.Join(controller, action+".html"), index}
+}
+
+// Return the views for the given url path, the last element
+// is the action and the others are the controller:
+//
+//	/               index/index.html
+//	/users          users/index.html
+//	/users/edit     users/edit.html, users/index.html
+//	/admin/users/   admin/users/index.html
+func PathViews(p string) []string {
+	p = strings.Trim(path.Clean("/"+p), "/")
+	if p == "" {
+		return ActionViews("index", "")
+	}
+	idx := strings.LastIndex(p, "/")
+	if idx < 0 {
+		return ActionViews(p, "")
+	}
+	return ActionViews(p[:idx], p[idx+1:])
+}
+
+// Set the controller and action that handle the request, used by
+// ConventionResolver
+func SetAction(req *http.Request, controller, action string) {
+	setValue(req, actionKey, [2]string{controller, action})
+}
+
+// Same as SetAction but returns a new request, req isn't changed
+func WithAction(req *http.Request, controller, action string) *http.Request {
+	return withValue(req, actionKey, [2]string{controller, action})
+}
+
+// Return the controller and action from SetAction, ok is false if
+// SetAction wasn't called
+func GetAction(req *http.Request) (controller, action string, ok bool) {
+	if v, has := getValue(req, actionKey); has {
+		pair := v.([2]string)
+		return pair[0], pair[1], true
+	}
+	return "", "", false
+}
@@ -0,0 +1,90 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"github.com/andrebq/webview"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestPathViews(t *testing.T) {
+	cases := map[string][]string{
+		"/":                  {"index/index.html"},
+		"":                   {"index/index.html"},
+		"/users":             {"users/index.html"},
+		"/users/edit":        {"users/edit.html", "users/index.html"},
+		"/users/index":       {"users/index.html"},
+		"/admin/users/edit/": {"admin/users/edit.html", "admin/users/index.html"},
+		"/users/../x/y":      {"x/y.html", "x/index.html"},
+	}
+	for p, expected := range cases {
+		if got := PathViews(p); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%q should resolve to %v but got %v", p, expected, got)
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	set := webview.TreeSet{}
+	for _, n := range []string{"users/index.html", "users/edit.html", "users/_row.html", "posts/index.html"} {
+		set[n] = nil
+	}
+	e := &Engine{Source: StaticSource(set, nil), Resolver: ConventionResolver}
+
+	view := func(path string, prepare func(req *http.Request)) string {
+		req, _ := http.NewRequest("GET", path, nil)
+		setValue(req, engineKey, e)
+		if prepare != nil {
+			prepare(req)
+		}
+		return GetViewName(req)
+	}
+
+	if v := view("/users/edit", nil); v != "users/edit.html" {
+		t.Errorf("unexpected view %v", v)
+	}
+	if v := view("/posts/edit", nil); v != "posts/index.html" {
+		t.Errorf("should fallback to the index of the controller but got %v", v)
+	}
+	if v := view("/", nil); v != "index/index.html" {
+		t.Errorf("unexpected view %v", v)
+	}
+	if v := view("/missing/page", nil); v != "missing/page.html" {
+		t.Errorf("a missing view should resolve to the first candidate but got %v", v)
+	}
+	if v := view("/whatever", func(req *http.Request) { SetAction(req, "users", "edit") }); v != "users/edit.html" {
+		t.Errorf("the action should take precedence over the path but got %v", v)
+	}
+	if v := view("/users/edit", func(req *http.Request) { SetViewName(req, "posts/index.html") }); v != "posts/index.html" {
+		t.Errorf("SetViewName should take precedence over the resolver but got %v", v)
+	}
+	if v := view("/users/_row", nil); v != "users/_row.html" {
+		t.Errorf("unexpected view %v", v)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/_row", nil)
+	e.Render(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("a url pointing to a partial should be a 404 but got %v %q", w.Code, w.Body.String())
+	}
+
+	e.Resolver = ViewResolverFunc(func(req *http.Request) []string {
+		return []string{"posts/edit.html", "posts/_form.html", "users/index.html"}
+	})
+	if v := view("/", nil); v != "users/index.html" {
+		t.Errorf("partials should be skipped but got %v", v)
+	}
+}
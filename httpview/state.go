@@ -0,0 +1,115 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// The values stored for a request, kept inside the request context
+//
+// The Set*/Register* functions change the state in place, the With*
+// functions copy it, so the request passed to them isn't changed.
+type state struct {
+	sync.Mutex
+	values map[key]interface{}
+}
+
+// Values that can't be shared by the copies of a state
+type cloner interface {
+	clone() interface{}
+}
+
+func (s *state) get(k key) (interface{}, bool) {
+	s.Lock()
+	defer s.Unlock()
+	v, ok := s.values[k]
+	return v, ok
+}
+
+func (s *state) set(k key, v interface{}) {
+	s.Lock()
+	defer s.Unlock()
+	s.values[k] = v
+}
+
+func (s *state) clone() *state {
+	s.Lock()
+	defer s.Unlock()
+	c := &state{values: make(map[key]interface{}, len(s.values))}
+	for k, v := range s.values {
+		if cl, ok := v.(cloner); ok {
+			v = cl.clone()
+		}
+		c.values[k] = v
+	}
+	return c
+}
+
+func newState() *state {
+	return &state{values: make(map[key]interface{})}
+}
+
+func stateOf(req *http.Request) *state {
+	s, _ := req.Context().Value(stateKey).(*state)
+	return s
+}
+
+// Return a copy of req holding a new empty state
+func withState(req *http.Request, s *state) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), stateKey, s))
+}
+
+// Return the value stored for the request
+func getValue(req *http.Request, k key) (interface{}, bool) {
+	if s := stateOf(req); s != nil {
+		return s.get(k)
+	}
+	return nil, false
+}
+
+// Change the value stored for the request, the state is provided
+// by Middleware
+func setValue(req *http.Request, k key, v interface{}) {
+	s := stateOf(req)
+	if s == nil {
+		// Fallback for handlers that don't use Middleware: the
+		// request is overwritten by a copy holding a new state, so
+		// the caller keeps seeing the values. This changes a struct
+		// owned by the server, which is why Middleware is required.
+		s = newState()
+		*req = *withState(req, s)
+	}
+	s.set(k, v)
+}
+
+// Return a copy of req with the value changed, req isn't changed
+func withValue(req *http.Request, k key, v interface{}) *http.Request {
+	var s *state
+	if old := stateOf(req); old != nil {
+		s = old.clone()
+	} else {
+		s = newState()
+	}
+	s.values[k] = v
+	return withState(req, s)
+}
+
+// Add a empty state to every request, required by the Set*/Register*
+// functions, without it they overwrite the request to replace its context.
+//
+// Same as DefaultEngine.Middleware
+func Middleware(h http.Handler) http.Handler {
+	return DefaultEngine.Middleware(h)
+}
@@ -0,0 +1,56 @@
+package httpview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testCtxKey struct{}
+
+func TestRequestState(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/", nil)
+	SetViewName(req, "users/index.html")
+	if GetViewName(req) != "users/index.html" {
+		t.Errorf("SetViewName should work without the middleware")
+	}
+
+	copied := req.WithContext(context.WithValue(req.Context(), testCtxKey{}, 1))
+	if GetViewName(copied) != "users/index.html" {
+		t.Errorf("values should survive WithContext")
+	}
+
+	other := WithViewName(req, "users/edit.html")
+	if GetViewName(other) != "users/edit.html" || GetViewName(req) != "users/index.html" {
+		t.Errorf("WithViewName should not change the original request")
+	}
+
+	var seen string
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if stateOf(req) == nil {
+			t.Errorf("the middleware should provide the state")
+		}
+		SetLayoutName(req, "admin/main.html")
+		seen = GetLayoutName(req)
+	}))
+	fresh, _ := http.NewRequest("GET", "/", nil)
+	h.ServeHTTP(httptest.NewRecorder(), fresh)
+	if seen != "admin/main.html" {
+		t.Errorf("unexpected layout %v", seen)
+	}
+	if stateOf(fresh) != nil {
+		t.Errorf("the middleware should not change the original request")
+	}
+}
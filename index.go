@@ -0,0 +1,73 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"sort"
+	"time"
+)
+
+// Information about the file that produced a template
+type Source struct {
+	// The file itself
+	File File
+	// The name of the file, as returned by TemplateName
+	Name string
+	// Delimiters used to parse the file
+	LeftDelim, RightDelim string
+	// The sha1 of the file contents, hex encoded
+	Hash string
+	// When the file was loaded
+	Loaded time.Time
+	// The front matter of the file, nil if the file
+	// don't have one
+	Meta *Meta
+}
+
+// Return the overlay layer that provided the file or -1
+// if the file didn't come from an OverlayDir
+func (s *Source) Layer() int {
+	if of, ok := s.File.(*OverlayFile); ok {
+		return of.Layer()
+	}
+	return -1
+}
+
+// Map each template name from a TreeSet to its source
+//
+// Files that define many templates have the same *Source
+// under all names.
+//
+// Set Options.Index to a non-nil Index to fill it while loading.
+type Index map[string]*Source
+
+// Compare two indexes and return the names of templates that
+// exist only in idx (added), only in old (removed) and the ones
+// whose contents changed
+func (idx Index) Diff(old Index) (added, removed, changed []string) {
+	for k, v := range idx {
+		if prev, has := old[k]; !has {
+			added = append(added, k)
+		} else if prev.Hash != v.Hash || prev.Name != v.Name {
+			changed = append(changed, k)
+		}
+	}
+	for k := range old {
+		if _, has := idx[k]; !has {
+			removed = append(removed, k)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return
+}
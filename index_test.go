@@ -0,0 +1,65 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	base := MapVFS{
+		"index.html":      []byte(`index`),
+		"partials/a.html": []byte(`{{ define "nice_button" }}a{{ end }}`),
+		"app.js":          []byte(`var a = 1;`),
+	}
+	theme := MapVFS{
+		"index.html": []byte(`themed index`),
+	}
+	opts := &Options{Index: make(Index)}
+	_, err := LoadDirWith(Overlay(base, theme), nil, nil, opts)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+
+	src := opts.Index["nice_button"]
+	if src == nil {
+		t.Fatalf("nice_button should be indexed")
+	}
+	if src.Name != "partials/a.html" || src != opts.Index["partials/a.html"] {
+		t.Errorf("nice_button should come from partials/a.html but got %v", src.Name)
+	}
+	if src.LeftDelim != "{{" || src.RightDelim != "}}" || src.Layer() != 0 {
+		t.Errorf("unexpected source %#v", src)
+	}
+	if js := opts.Index["app.js"]; js.LeftDelim != "<%" || js.RightDelim != "%>" {
+		t.Errorf("unexpected delimiters for app.js %v / %v", js.LeftDelim, js.RightDelim)
+	}
+	if opts.Index["index.html"].Layer() != 1 {
+		t.Errorf("index.html should come from the theme")
+	}
+
+	old := opts.Index
+	base["users/list.html"] = []byte(`users`)
+	base["partials/a.html"] = []byte(`{{ define "nice_button" }}b{{ end }}`)
+	delete(base, "app.js")
+	opts.Index = make(Index)
+	if _, err := LoadDirWith(Overlay(base, theme), nil, nil, opts); err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	added, removed, changed := opts.Index.Diff(old)
+	if !reflect.DeepEqual(added, []string{"users/list.html"}) ||
+		!reflect.DeepEqual(removed, []string{"app.js"}) ||
+		!reflect.DeepEqual(changed, []string{"nice_button", "partials/a.html"}) {
+		t.Errorf("unexpected diff %v / %v / %v", added, removed, changed)
+	}
+}
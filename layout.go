@@ -0,0 +1,164 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// Return the chain of layouts starting at name: name itself, its layout,
+// the layout of its layout and so on.
+//
+// Layouts can extend other layouts by declaring its parent in the
+// front matter:
+//
+//	admin/main.html
+//	---
+//	layout: layout/main.html
+//	---
+//	<div class="admin">{{ template "contents" . }}</div>
+//
+//	layout/main.html
+//	<html><body>{{ template "contents" . }}</body></html>
+//
+// When the alias map has YieldName(layout), the calls to "contents" made
+// by that layout are renamed to YieldName(layout) while building the
+// template, so each level of the chain can render a different template.
+// ChainAlias builds the alias map that links the levels, without it a
+// layout renders "contents" like any other layout.
+//
+// The front matter is read from the index, an error is returned if a
+// layout is missing or if the chain has a cycle.
+func LayoutChain(idx Index, name string) ([]string, error) {
+	return layoutChain(name, func(name string) (*Meta, bool) {
+		src, has := idx[name]
+		if src == nil {
+			return nil, has
+		}
+		return src.Meta, has
+	})
+}
+
+// Same as LayoutChain but the front matter is read from the
+// set (see MetaOf)
+func LayoutChainOf(set TreeSet, name string) ([]string, error) {
+	return layoutChain(name, func(name string) (*Meta, bool) {
+		_, has := set[name]
+		return MetaOf(set, name), has
+	})
+}
+
+// Follow the layouts declared by each template, lookup returns the
+// front matter of a template and if it exists
+func layoutChain(name string, lookup func(name string) (*Meta, bool)) ([]string, error) {
+	chain := []string{name}
+	seen := map[string]bool{name: true}
+	for {
+		meta, _ := lookup(name)
+		if meta == nil || meta.Layout == "" {
+			return chain, nil
+		}
+		parent := meta.Layout
+		if _, has := lookup(parent); !has {
+			return nil, fmt.Errorf("%v: layout %v not found", name, parent)
+		}
+		chain = append(chain, parent)
+		if seen[parent] {
+			return nil, fmt.Errorf("layout cycle: %v", strings.Join(chain, " -> "))
+		}
+		seen[parent] = true
+		name = parent
+	}
+}
+
+// Return the name used by a layout that extends another layout
+// to render its contents
+func YieldName(layout string) string {
+	return "contents:" + layout
+}
+
+// Return the alias map used to render the chain returned by LayoutChain,
+// the first element is the view and the last one is the root layout.
+//
+// "main" points to the root layout, "contents" to the level below it and
+// YieldName(layout) points to the level below that layout.
+func ChainAlias(chain []string) map[string]string {
+	alias := make(map[string]string)
+	if len(chain) == 0 {
+		return alias
+	}
+	last := len(chain) - 1
+	alias["main"] = chain[last]
+	if last > 0 {
+		alias["contents"] = chain[last-1]
+	}
+	for i := 1; i < last; i++ {
+		alias[YieldName(chain[i])] = chain[i-1]
+	}
+	return alias
+}
+
+// Check the layout chain of every template from the index, returns
+// the first missing layout or cycle found
+func CheckLayouts(idx Index) error {
+	names := make([]string, 0, len(idx))
+	for k := range idx {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if _, err := LayoutChain(idx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return a copy of set where the layouts linked by the alias map
+// (see ChainAlias) call YieldName(layout) instead of "contents", the
+// trees from set aren't changed. If alias don't link any layout, set
+// is returned.
+func linkLayouts(set TreeSet, alias map[string]string) TreeSet {
+	linked, copied := set, false
+	for k := range alias {
+		if !strings.HasPrefix(k, YieldName("")) {
+			continue
+		}
+		layout := k[len(YieldName("")):]
+		tree, has := set[layout]
+		if !has || tree.Root == nil {
+			continue
+		}
+		if !copied {
+			copied = true
+			linked = make(TreeSet, len(set))
+			for n, t := range set {
+				linked[n] = t
+			}
+		}
+		linked[layout] = renameYield(layout, tree.Copy())
+	}
+	return linked
+}
+
+// Rename the calls to "contents" from the layout, returns tree
+func renameYield(layout string, tree *parse.Tree) *parse.Tree {
+	walkNodes(tree.Root, func(n parse.Node) {
+		if tn, ok := n.(*parse.TemplateNode); ok && tn.Name == "contents" {
+			tn.Name = YieldName(layout)
+		}
+	})
+	return tree
+}
@@ -0,0 +1,129 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLayoutChain(t *testing.T) {
+	opts := &Options{Index: make(Index)}
+	set, err := LoadDirWith(MapVFS{
+		"layout/main.html": []byte(`<html>{{ template "contents" . }}</html>`),
+		"admin/main.html":  []byte("---\nlayout: layout/main.html\n---\n<div>{{ template \"contents\" . }}</div>"),
+		"admin/users.html": []byte("---\nlayout: admin/main.html\n---\nusers"),
+	}, nil, nil, opts)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+
+	chain, err := LayoutChain(opts.Index, "admin/users.html")
+	if err != nil {
+		t.Fatalf("unable to resolve chain %v", err)
+	}
+	if !reflect.DeepEqual(chain, []string{"admin/users.html", "admin/main.html", "layout/main.html"}) {
+		t.Errorf("unexpected chain %v", chain)
+	}
+	alias := ChainAlias(chain)
+	if !reflect.DeepEqual(alias, map[string]string{
+		"main":                     "layout/main.html",
+		"contents":                 "admin/main.html",
+		"contents:admin/main.html": "admin/users.html",
+	}) {
+		t.Errorf("unexpected alias %v", alias)
+	}
+
+	tmpl, err := Template(set, alias)
+	if err != nil {
+		t.Fatalf("unable to build template %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.ExecuteTemplate(buf, "main", nil); err != nil {
+		t.Fatalf("unable to render %v", err)
+	}
+	if buf.String() != "<html><div>users</div></html>" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	// without the chain, the layout renders "contents" like any other
+	direct := map[string]string{"main": "admin/main.html", "contents": "admin/users.html"}
+	if err := Validate(set, direct); err != nil {
+		t.Errorf("the layout should be valid without the chain but got %v", err)
+	}
+	tmpl, err = Template(set, direct)
+	if err != nil {
+		t.Fatalf("unable to build template %v", err)
+	}
+	buf.Reset()
+	if err := tmpl.ExecuteTemplate(buf, "main", nil); err != nil {
+		t.Fatalf("unable to render %v", err)
+	}
+	if buf.String() != "<div>users</div>" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestCheckLayouts(t *testing.T) {
+	_, err := LoadDirWith(MapVFS{
+		"a.html": []byte("---\nlayout: b.html\n---\na"),
+		"b.html": []byte("---\nlayout: a.html\n---\nb"),
+	}, nil, nil, &Options{Index: make(Index)})
+	if err == nil || !strings.Contains(err.Error(), "layout cycle: a.html -> b.html -> a.html") {
+		t.Errorf("expecting a cycle but got %v", err)
+	}
+
+	_, err = LoadDirWith(MapVFS{
+		"a.html": []byte("---\nlayout: missing.html\n---\na"),
+	}, nil, nil, &Options{Index: make(Index)})
+	if err == nil || err.Error() != "a.html: layout missing.html not found" {
+		t.Errorf("expecting a missing layout but got %v", err)
+	}
+
+	// the layouts are checked even without an index
+	_, err = LoadDir(MapVFS{
+		"a.html": []byte("---\nlayout: b.html\n---\na"),
+		"b.html": []byte("---\nlayout: a.html\n---\nb"),
+	}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "layout cycle") {
+		t.Errorf("expecting a cycle but got %v", err)
+	}
+	_, err = LoadDir(MapVFS{
+		"c.html": []byte("---\nlayout: missing.html\n---\nc"),
+	}, nil, nil)
+	if err == nil || err.Error() != "c.html: layout missing.html not found" {
+		t.Errorf("expecting a missing layout but got %v", err)
+	}
+}
+
+func TestLayoutChainOf(t *testing.T) {
+	set, err := LoadDir(MapVFS{
+		"layout/main.html": []byte(`<html>{{ template "contents" . }}</html>`),
+		"admin/main.html":  []byte("---\nlayout: layout/main.html\n---\n<div>{{ template \"contents\" . }}</div>"),
+		"admin/users.html": []byte("---\nlayout: admin/main.html\ntitle: a */ b\n---\nusers"),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	if meta := MetaOf(set, "admin/users.html"); meta == nil || meta.Title != "a */ b" {
+		t.Errorf("unexpected meta %#v", meta)
+	}
+	if meta := MetaOf(set, "layout/main.html"); meta != nil {
+		t.Errorf("layout/main.html don't have a front matter but got %#v", meta)
+	}
+	chain, err := LayoutChainOf(set, "admin/users.html")
+	if err != nil || !reflect.DeepEqual(chain, []string{"admin/users.html", "admin/main.html", "layout/main.html"}) {
+		t.Errorf("unexpected chain %v %v", chain, err)
+	}
+}
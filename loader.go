@@ -0,0 +1,230 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	tt "text/template"
+	"time"
+)
+
+// Files that know when they were modified.
+//
+// The Loader uses this to avoid reading the contents of every
+// file when checking for changes.
+type ModTimer interface {
+	// Return the last modification time
+	ModTime() (time.Time, error)
+}
+
+// Keep a TreeSet in sync with the files from a Dir
+//
+// The set returned by Set is never modified, every reload creates
+// a new TreeSet and replaces the old one, so it is safe to use the set
+// from many goroutines while a reload is happening.
+//
+// If a reload fails, the previous set is kept and the error is reported
+// using OnError.
+type Loader struct {
+	// The root dir
+	Dir Dir
+	// Functions passed to LoadDir
+	Funcs tt.FuncMap
+	// Filter passed to LoadDir
+	Filter Filter
+	// Options passed to LoadDirWith, might be nil.
+	//
+	// Options.Index is ignored, every reload fills a new
+	// Index, available from the Index method
+	Options *Options
+	// Called when a reload fails, might be nil
+	OnError func(err error)
+
+	// serialize the calls to Check/Reload
+	mu      sync.Mutex
+	current atomic.Value
+	stamp   []byte
+	err     error
+}
+
+// Create a new loader and load the first set, if the first load fails
+// an error is returned.
+func NewLoader(dir Dir, funcs tt.FuncMap, filter Filter) (*Loader, error) {
+	l := &Loader{Dir: dir, Funcs: funcs, Filter: filter}
+	if err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// the set and index swapped at every reload
+type loaded struct {
+	set   TreeSet
+	index Index
+}
+
+// Return the last set loaded without errors, might be nil
+// if nothing was loaded
+func (l *Loader) Set() TreeSet {
+	if cur, ok := l.current.Load().(*loaded); ok {
+		return cur.set
+	}
+	return nil
+}
+
+// Return the index of the set returned by Set
+func (l *Loader) Index() Index {
+	if cur, ok := l.current.Load().(*loaded); ok {
+		return cur.index
+	}
+	return nil
+}
+
+// Return the error from the last reload, nil if the last
+// reload was successful
+func (l *Loader) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Load all files again, even if nothing changed
+func (l *Loader) Reload() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stamp, err := l.computeStamp()
+	if err != nil {
+		return l.fail(err)
+	}
+	return l.reload(stamp)
+}
+
+// Check if any file changed since the last load, if so, reload
+// the set.
+//
+// Return true only if a new set was loaded
+func (l *Loader) Check() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stamp, err := l.computeStamp()
+	if err != nil {
+		return false, l.fail(err)
+	}
+	if string(stamp) == string(l.stamp) {
+		return false, l.err
+	}
+	if err := l.reload(stamp); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Call Check at every interval until stop is called
+func (l *Loader) Watch(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.Check()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+func (l *Loader) reload(stamp []byte) error {
+	var opts Options
+	if l.Options != nil {
+		opts = *l.Options
+	}
+	opts.Index = make(Index)
+	set, err := LoadDirWith(l.Dir, l.Funcs, l.Filter, &opts)
+	// even when the load fails, remember the stamp to avoid
+	// parsing the same broken files at every check
+	l.stamp = stamp
+	if err != nil {
+		return l.fail(err)
+	}
+	l.current.Store(&loaded{set: set, index: opts.Index})
+	l.err = nil
+	return nil
+}
+
+func (l *Loader) fail(err error) error {
+	l.err = err
+	if l.OnError != nil {
+		l.OnError(err)
+	}
+	return err
+}
+
+// Compute a fingerprint of all files accepted by the filter,
+// using the modification time when available (and not zero) or the
+// contents otherwise
+func (l *Loader) computeStamp() ([]byte, error) {
+	h := sha1.New()
+	err := stampDir(h, l.Dir, l.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func stampDir(w io.Writer, dir Dir, filter Filter) error {
+	files, err := dir.ReadFiles()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if filter != nil && !filter.Filter(file) {
+			continue
+		}
+		fmt.Fprintf(w, "%v\x00", TemplateName(file))
+		if mt, ok := file.(ModTimer); ok {
+			// some fs.FS (embed.FS, fstest.MapFS) don't track
+			// modification times and return a zero time
+			if when, err := mt.ModTime(); err == nil && !when.IsZero() {
+				fmt.Fprintf(w, "%v\x00", when.UnixNano())
+				continue
+			}
+		}
+		contents, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%x\x00", sha1.Sum(contents))
+	}
+	dirs, err := dir.ReadDirs()
+	if err != nil {
+		return err
+	}
+	for _, cdir := range dirs {
+		if filter == nil || filter.Filter(cdir) {
+			if err := stampDir(w, cdir, filter); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
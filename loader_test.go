@@ -0,0 +1,87 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoader(t *testing.T) {
+	vfs := MapVFS{
+		"index.html": []byte(`v1`),
+	}
+	l, err := NewLoader(vfs, nil, FilterFunc(AllowHtmlJsAndCss))
+	if err != nil {
+		t.Fatalf("unable to create loader %v", err)
+	}
+	var reported error
+	l.OnError = func(err error) { reported = err }
+
+	first := l.Set()
+	if _, has := first["index.html"]; !has {
+		t.Fatalf("index.html should be loaded")
+	}
+	if l.Index()["index.html"] == nil {
+		t.Errorf("index.html should be indexed")
+	}
+
+	if changed, err := l.Check(); changed || err != nil {
+		t.Errorf("nothing changed but got %v / %v", changed, err)
+	}
+
+	vfs["users/list.html"] = []byte(`users`)
+	if changed, err := l.Check(); !changed || err != nil {
+		t.Fatalf("a new file should trigger a reload but got %v / %v", changed, err)
+	}
+	second := l.Set()
+	if _, has := second["users/list.html"]; !has {
+		t.Errorf("users/list.html should be loaded")
+	}
+	if _, has := first["users/list.html"]; has {
+		t.Errorf("the previous set must not be modified")
+	}
+
+	vfs["index.html"] = []byte(`{{ broken `)
+	if changed, err := l.Check(); changed || err == nil {
+		t.Fatalf("a broken file should fail the reload but got %v / %v", changed, err)
+	}
+	if reported == nil || l.Err() == nil {
+		t.Errorf("the error should be reported")
+	}
+	if _, has := l.Set()["users/list.html"]; !has {
+		t.Errorf("the last good set should be kept")
+	}
+
+	vfs["index.html"] = []byte(`v2`)
+	if changed, err := l.Check(); !changed || err != nil {
+		t.Fatalf("fixing the file should trigger a reload but got %v / %v", changed, err)
+	}
+	if l.Err() != nil {
+		t.Errorf("the error should be cleared after a good reload")
+	}
+}
+
+func TestLoaderZeroModTime(t *testing.T) {
+	// fstest.MapFS returns a zero ModTime, like embed.FS
+	fsys := fstest.MapFS{
+		"index.html": &fstest.MapFile{Data: []byte(`v1`)},
+	}
+	l, err := NewLoader(FS(fsys), nil, FilterFunc(AllowHtmlJsAndCss))
+	if err != nil {
+		t.Fatalf("unable to create loader %v", err)
+	}
+	fsys["index.html"] = &fstest.MapFile{Data: []byte(`v2`)}
+	if changed, err := l.Check(); !changed || err != nil {
+		t.Errorf("changing the contents should trigger a reload but got %v / %v", changed, err)
+	}
+}
@@ -12,11 +12,13 @@ package webview
 // subject to the following conditions:
 
 import (
+	"crypto/sha1"
 	"fmt"
 	"html/template"
 	"strings"
 	tt "text/template"
 	"text/template/parse"
+	"time"
 	"unicode/utf8"
 )
 
@@ -115,8 +117,23 @@ func (ff FilterFunc) Filter(f Namer) bool {
 //
 // If you need a new template with a different alias, just call this function again
 // passing a different alias map
+//
+// Building the template isn't cheap, use a Renderer to reuse them.
+//
+// Use ExecuteContext to render js, json and css templates with
+// the correct escaping.
 func Template(set TreeSet, alias map[string]string) (*template.Template, error) {
-	t, _ := template.New("_root").Parse("")
+	return TemplateFuncs(set, alias, nil)
+}
+
+// Same as Template but the functions are added to the template, they should
+// be the same functions used to load the set
+func TemplateFuncs(set TreeSet, alias map[string]string, funcs tt.FuncMap) (*template.Template, error) {
+	t := template.New("_root")
+	bindFuncs(t)
+	t.Funcs(template.FuncMap(funcs))
+	t.Parse("")
+	set = linkLayouts(set, alias)
 	for k, v := range set {
 		if _, err := t.AddParseTree(k, v); err != nil {
 			return t, err
@@ -129,6 +146,9 @@ func Template(set TreeSet, alias map[string]string) (*template.Template, error)
 			}
 		}
 	}
+	if err := addContextWrappers(t, set, alias); err != nil {
+		return t, err
+	}
 	return t, nil
 }
 
@@ -136,25 +156,52 @@ func Template(set TreeSet, alias map[string]string) (*template.Template, error)
 // if two templates have the same name (let's say that file a.html
 // and b.html both define the template "nice_button"). Only one
 // of those definitions will be available (the last one returned by
-// the vfs)
+// the vfs), use LoadDirWith to select a different ConflictPolicy.
 //
 // Each template can be accessed by its full path from root,
 // that means "layout/body.html" represents a file under
 // "layout" with a name of "body.html"
+//
+// The layouts declared in the front matter of each file must exist and
+// can't form a cycle (see CheckLayouts).
 func LoadDir(root Dir, funcs tt.FuncMap, filter Filter) (TreeSet, error) {
+	return LoadDirWith(root, funcs, filter, nil)
+}
+
+// Same as LoadDir but using the given options, opts might be nil
+//
+// The layouts declared by each file are checked with CheckLayouts,
+// using opts.Index or a private index if it is nil.
+func LoadDirWith(root Dir, funcs tt.FuncMap, filter Filter, opts *Options) (TreeSet, error) {
+	if opts == nil || opts.Index == nil {
+		private := Options{}
+		if opts != nil {
+			private = *opts
+		}
+		private.Index = make(Index)
+		opts = &private
+	}
 	set := make(TreeSet)
-	return set, LoadDirInto(set, root, funcs, filter)
+	if err := LoadDirIntoWith(set, root, funcs, filter, opts); err != nil {
+		return set, err
+	}
+	return set, CheckLayouts(opts.Index)
 }
 
 // Load all files from the given Dir into the given template
 func LoadDirInto(t TreeSet, dir Dir, funcs tt.FuncMap, filter Filter) error {
+	return LoadDirIntoWith(t, dir, funcs, filter, nil)
+}
+
+// Same as LoadDirInto but using the given options, opts might be nil
+func LoadDirIntoWith(t TreeSet, dir Dir, funcs tt.FuncMap, filter Filter, opts *Options) error {
 	files, err := dir.ReadFiles()
 	if err != nil {
 		return err
 	}
 	for _, file := range files {
 		if filter == nil || filter.Filter(file) {
-			err = LoadFileInto(t, file, funcs)
+			err = LoadFileIntoWith(t, file, funcs, opts)
 			if err != nil {
 				return err
 			}
@@ -166,7 +213,7 @@ func LoadDirInto(t TreeSet, dir Dir, funcs tt.FuncMap, filter Filter) error {
 	}
 	for _, cdir := range dirs {
 		if filter == nil || filter.Filter(cdir) {
-			err = LoadDirInto(t, cdir, funcs, filter)
+			err = LoadDirIntoWith(t, cdir, funcs, filter, opts)
 			if err != nil {
 				return err
 			}
@@ -177,8 +224,14 @@ func LoadDirInto(t TreeSet, dir Dir, funcs tt.FuncMap, filter Filter) error {
 
 // Read a file and register a new template under the filename
 //
-// The name is given by TemplateName(f)
+// The name is given by TemplateName(f), if the file starts with
+// a front matter (see Meta) it is removed before parsing.
 func LoadFileInto(t TreeSet, f File, funcs tt.FuncMap) error {
+	return LoadFileIntoWith(t, f, funcs, nil)
+}
+
+// Same as LoadFileInto but using the given options, opts might be nil
+func LoadFileIntoWith(t TreeSet, f File, funcs tt.FuncMap, opts *Options) error {
 	name := TemplateName(f)
 	contents, err := f.Contents()
 	if err != nil {
@@ -187,17 +240,39 @@ func LoadFileInto(t TreeSet, f File, funcs tt.FuncMap) error {
 	if !utf8.Valid(contents) {
 		return fmt.Errorf("file %v must be encoded using utf8", name)
 	}
-	leftDelim, rightDelim := DiscoverDelim(name)
-	treeSet, err := parse.Parse(name,
-		string(contents),
+	leftDelim, rightDelim := opts.delims().Lookup(name)
+	meta, text, err := splitFrontMatter(string(contents), leftDelim, rightDelim)
+	if err != nil {
+		return fmt.Errorf("file %v has an invalid front matter: %v", name, err)
+	}
+	// comments are kept, the front matter is one of them
+	tree := parse.New(name)
+	tree.Mode = parse.ParseComments
+	treeSet := make(map[string]*parse.Tree)
+	_, err = tree.Parse(text,
 		leftDelim,
 		rightDelim,
+		treeSet,
+		builtinFuncs,
 		funcs)
 	if err != nil {
 		return err
 	}
+	src := &Source{
+		File:       f,
+		Name:       name,
+		LeftDelim:  leftDelim,
+		RightDelim: rightDelim,
+		Hash:       fmt.Sprintf("%x", sha1.Sum(contents)),
+		Loaded:     time.Now(),
+		Meta:       meta,
+	}
 	for k, v := range treeSet {
-		t[k] = v
+		k = renameSection(name, k, v)
+		resolvePartials(name, v)
+		if err := opts.add(t, k, v, src); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -225,15 +300,10 @@ func TemplateName(f Namer) string {
 // HTML => {{ / }}
 // JS => <% / %>
 // CSS => <% / %>
+//
+// Use Options.Delims to change those rules
 func DiscoverDelim(name string) (string, string) {
-	if strings.HasSuffix(name, ".html") {
-		return "{{", "}}"
-	} else if strings.HasSuffix(name, ".js") || strings.HasSuffix(name, ".json") {
-		return "<%", "%>"
-	} else if strings.HasSuffix(name, ".css") {
-		return "<%", "%>"
-	}
-	return "{{", "}}"
+	return defaultDelims.Lookup(name)
 }
 
 // Filter function that allow only html,(js/json) and css files
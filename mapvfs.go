@@ -0,0 +1,145 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// A in-memory vfs, the keys are slash separated paths and the
+// values are the contents of each file.
+//
+// Directories are implied by the paths, so
+//
+//	MapVFS{
+//		"index.html": []byte("..."),
+//		"layout/main.html": []byte("..."),
+//	}
+//
+// Have one file at the root and one directory called "layout".
+//
+// The MapVFS itself is the root directory.
+type MapVFS map[string][]byte
+
+func (m MapVFS) IsDir() bool {
+	return true
+}
+
+func (m MapVFS) Name() string {
+	return ""
+}
+
+func (m MapVFS) Parent() Dir {
+	return nil
+}
+
+func (m MapVFS) ReadDirs() ([]Dir, error) {
+	return (&mapvfs{m: m}).ReadDirs()
+}
+
+func (m MapVFS) ReadFiles() ([]File, error) {
+	return (&mapvfs{m: m}).ReadFiles()
+}
+
+// a entry inside a MapVFS, cd is the clean path without
+// leading or trailing slashes, the root is ""
+type mapvfs struct {
+	m      MapVFS
+	cd     string
+	isfile bool
+}
+
+func (vfs *mapvfs) IsDir() bool {
+	return !vfs.isfile
+}
+
+func (vfs *mapvfs) Name() string {
+	if vfs.cd == "" {
+		return ""
+	}
+	return path.Base(vfs.cd)
+}
+
+func (vfs *mapvfs) Parent() Dir {
+	if vfs.cd == "" {
+		return nil
+	}
+	parent := path.Dir(vfs.cd)
+	if parent == "." {
+		return vfs.m
+	}
+	return &mapvfs{m: vfs.m, cd: parent}
+}
+
+func (vfs *mapvfs) ReadDirs() ([]Dir, error) {
+	names := vfs.children(true)
+	ret := make([]Dir, 0, len(names))
+	for _, n := range names {
+		ret = append(ret, &mapvfs{m: vfs.m, cd: path.Join(vfs.cd, n)})
+	}
+	return ret, nil
+}
+
+func (vfs *mapvfs) ReadFiles() ([]File, error) {
+	names := vfs.children(false)
+	ret := make([]File, 0, len(names))
+	for _, n := range names {
+		ret = append(ret, &mapvfs{m: vfs.m, cd: path.Join(vfs.cd, n), isfile: true})
+	}
+	return ret, nil
+}
+
+func (vfs *mapvfs) Contents() ([]byte, error) {
+	for k, v := range vfs.m {
+		if cleanMapPath(k) == vfs.cd {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("%v not found", vfs.cd)
+}
+
+// return the sorted names of the direct children of cd
+func (vfs *mapvfs) children(dirs bool) []string {
+	prefix := vfs.cd
+	if prefix != "" {
+		prefix += "/"
+	}
+	seen := make(map[string]bool)
+	for k := range vfs.m {
+		k = cleanMapPath(k)
+		if k == "" || !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		rest := k[len(prefix):]
+		idx := strings.Index(rest, "/")
+		if dirs && idx > 0 {
+			seen[rest[:idx]] = true
+		} else if !dirs && idx < 0 {
+			seen[rest] = true
+		}
+	}
+	ret := make([]string, 0, len(seen))
+	for k := range seen {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// normalize the keys of a MapVFS
+func cleanMapPath(p string) string {
+	p = path.Clean("/" + p)
+	return strings.TrimPrefix(p, "/")
+}
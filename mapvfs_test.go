@@ -0,0 +1,87 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMapVFS(t *testing.T) {
+	vfs := MapVFS{
+		"index.html":            []byte(`{{ template "layout/index.html" . }}`),
+		"layout/index.html":     []byte(`<!doctype html><html></html>`),
+		"/layout/partial/a.css": []byte(`body {}`),
+	}
+
+	files, err := vfs.ReadFiles()
+	if err != nil {
+		t.Fatalf("unable to read vfs files %v", err)
+	}
+	if len(files) != 1 || files[0].Name() != "index.html" {
+		t.Fatalf("expecting only index.html but got %v", files)
+	}
+	if files[0].Parent() == nil {
+		t.Errorf("files should have a parent")
+	}
+
+	dirs, err := vfs.ReadDirs()
+	if err != nil {
+		t.Fatalf("unable to read vfs dirs %v", err)
+	}
+	if len(dirs) != 1 || dirs[0].Name() != "layout" {
+		t.Fatalf("expecting only layout but got %v", dirs)
+	}
+
+	dirs, err = dirs[0].ReadDirs()
+	if err != nil {
+		t.Fatalf("unable to read child dirs %v", err)
+	}
+	if len(dirs) != 1 {
+		t.Fatalf("expecting one child dir but got %v", len(dirs))
+	}
+	files, err = dirs[0].ReadFiles()
+	if err != nil {
+		t.Fatalf("unable to read child files %v", err)
+	}
+	if name := TemplateName(files[0]); name != "layout/partial/a.css" {
+		t.Errorf("name should be layout/partial/a.css but got %v", name)
+	}
+	if name := TemplateName(files[0].Parent().Parent()); name != "layout/" {
+		t.Errorf("name should be layout/ but got %v", name)
+	}
+	if contents, err := files[0].Contents(); err != nil || string(contents) != "body {}" {
+		t.Errorf("unexpected contents %q / %v", contents, err)
+	}
+}
+
+func TestLoadDirFromMapVFS(t *testing.T) {
+	set, err := LoadDir(MapVFS{
+		"index.html":        []byte(`{{ template "layout/index.html" . }}`),
+		"layout/index.html": []byte(`<!doctype html><html>{{ . }}</html>`),
+	}, nil, FilterFunc(AllowHtmlJsAndCss))
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+
+	tmpl, err := Template(set, map[string]string{"main": "index.html"})
+	if err != nil {
+		t.Fatalf("unable to build template %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.ExecuteTemplate(buf, "main", "hi"); err != nil {
+		t.Fatalf("unable to render using alias %v", err)
+	}
+	if buf.String() != "<!doctype html><html>hi</html>" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
@@ -0,0 +1,118 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"fmt"
+	"text/template/parse"
+)
+
+// Select what happens when two files define a template
+// with the same name
+type ConflictPolicy int
+
+const (
+	// The last definition returned by the vfs is kept,
+	// this is the default.
+	LastWins = ConflictPolicy(iota)
+	// The first definition returned by the vfs is kept
+	FirstWins
+	// Stop loading and return a *ConflictError
+	FailOnConflict
+)
+
+// Extra options used by the Load*With functions
+type Options struct {
+	// What to do when two files define the same template
+	Conflict ConflictPolicy
+	// If not nil, it is called for every conflict before
+	// applying the policy, useful to log a warning
+	OnConflict func(err *ConflictError)
+	// If not nil, it is filled with the source of every
+	// template added to the set
+	Index Index
+	// Delimiters used for each file, if nil, DiscoverDelim
+	// is used
+	Delims *Delims
+}
+
+// Two files defining the same template
+type ConflictError struct {
+	// The name of the template
+	Name string
+	// Location (file:line:col) of the definition already
+	// in the set
+	Previous string
+	// Location (file:line:col) of the new definition
+	Current string
+}
+
+func (c *ConflictError) Error() string {
+	return fmt.Sprintf("template %v defined twice: %v and %v", c.Name, c.Previous, c.Current)
+}
+
+func (o *Options) delims() *Delims {
+	if o == nil || o.Delims == nil {
+		return defaultDelims
+	}
+	return o.Delims
+}
+
+// Add the tree to the set, handling conflicts
+func (o *Options) add(t TreeSet, name string, tree *parse.Tree, src *Source) error {
+	old, has := t[name]
+	if !has || parse.IsEmptyTree(old.Root) {
+		o.store(t, name, tree, src)
+		return nil
+	}
+	if parse.IsEmptyTree(tree.Root) {
+		// just a file with some definitions, keep
+		// the old one
+		return nil
+	}
+	policy := LastWins
+	if o != nil {
+		policy = o.Conflict
+	}
+	cerr := &ConflictError{
+		Name:     name,
+		Previous: treeLocation(old),
+		Current:  treeLocation(tree),
+	}
+	if o != nil && o.OnConflict != nil {
+		o.OnConflict(cerr)
+	}
+	switch policy {
+	case FirstWins:
+		return nil
+	case FailOnConflict:
+		return cerr
+	}
+	o.store(t, name, tree, src)
+	return nil
+}
+
+func (o *Options) store(t TreeSet, name string, tree *parse.Tree, src *Source) {
+	t[name] = tree
+	if o != nil && o.Index != nil {
+		o.Index[name] = src
+	}
+}
+
+// Return the file:line:col where the tree is defined
+func treeLocation(tree *parse.Tree) string {
+	if tree.Root == nil {
+		return tree.ParseName
+	}
+	loc, _ := tree.ErrorContext(tree.Root)
+	return loc
+}
@@ -0,0 +1,72 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"testing"
+)
+
+var conflictVFS = MapVFS{
+	"partials/a.html": []byte(`{{ define "nice_button" }}a{{ end }}`),
+	"partials/b.html": []byte("\n{{ define \"nice_button\" }}b{{ end }}"),
+	"index.html":      []byte(`{{ template "nice_button" }}`),
+}
+
+func renderConflict(t *testing.T, set TreeSet) string {
+	tmpl, err := Template(set, nil)
+	if err != nil {
+		t.Fatalf("unable to build template %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.ExecuteTemplate(buf, "index.html", nil); err != nil {
+		t.Fatalf("unable to render template %v", err)
+	}
+	return buf.String()
+}
+
+func TestConflictPolicy(t *testing.T) {
+	var warned []*ConflictError
+	opts := &Options{
+		Conflict:   FirstWins,
+		OnConflict: func(err *ConflictError) { warned = append(warned, err) },
+	}
+	set, err := LoadDirWith(conflictVFS, nil, nil, opts)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	if out := renderConflict(t, set); out != "a" {
+		t.Errorf("first definition should win but got %q", out)
+	}
+	if len(warned) != 1 {
+		t.Fatalf("expecting one conflict but got %v", len(warned))
+	}
+
+	set, err = LoadDirWith(conflictVFS, nil, nil, &Options{Conflict: LastWins})
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	if out := renderConflict(t, set); out != "b" {
+		t.Errorf("last definition should win but got %q", out)
+	}
+
+	_, err = LoadDirWith(conflictVFS, nil, nil, &Options{Conflict: FailOnConflict})
+	cerr, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("expecting a conflict error but got %v", err)
+	}
+	if cerr.Name != "nice_button" ||
+		cerr.Previous != "partials/a.html:1:26" ||
+		cerr.Current != "partials/b.html:2:26" {
+		t.Errorf("unexpected conflict %v", cerr)
+	}
+}
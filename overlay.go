@@ -0,0 +1,181 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A union of several Dir's.
+//
+// Layers are stacked in the order they are given, so files from later
+// layers shadow the files with the same name from earlier ones. Sub-directories
+// are merged, which means that a theme only needs to provide the files
+// it wants to override:
+//
+//	base/layout/main.html
+//	base/index/index.html
+//	theme/layout/main.html
+//
+//	Overlay(base, theme)
+//
+// Will load "layout/main.html" from theme and "index/index.html" from base.
+type OverlayDir struct {
+	// the directory from each layer, nil if the layer
+	// don't have this directory
+	layers []Dir
+	parent *OverlayDir
+	name   string
+}
+
+// A file returned by a OverlayDir
+type OverlayFile struct {
+	File
+	parent *OverlayDir
+	layer  int
+}
+
+// Create a new union of all layers, the first layer is the
+// bottom one.
+func Overlay(layers ...Dir) *OverlayDir {
+	return &OverlayDir{layers: layers}
+}
+
+func (o *OverlayDir) IsDir() bool {
+	return true
+}
+
+func (o *OverlayDir) Name() string {
+	return o.name
+}
+
+func (o *OverlayDir) Parent() Dir {
+	if o.parent == nil {
+		return nil
+	}
+	return o.parent
+}
+
+// Return the merged sub-directories from all layers
+func (o *OverlayDir) ReadDirs() ([]Dir, error) {
+	childs := make(map[string][]Dir)
+	for i, l := range o.layers {
+		if l == nil {
+			continue
+		}
+		dirs, err := l.ReadDirs()
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dirs {
+			if _, has := childs[d.Name()]; !has {
+				childs[d.Name()] = make([]Dir, len(o.layers))
+			}
+			childs[d.Name()][i] = d
+		}
+	}
+	names := make([]string, 0, len(childs))
+	for k := range childs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	ret := make([]Dir, 0, len(names))
+	for _, n := range names {
+		ret = append(ret, &OverlayDir{layers: childs[n], parent: o, name: n})
+	}
+	return ret, nil
+}
+
+// Return the files from all layers, when two layers have a file with the
+// same name, the one from the upper layer wins
+func (o *OverlayDir) ReadFiles() ([]File, error) {
+	childs := make(map[string]*OverlayFile)
+	for i, l := range o.layers {
+		if l == nil {
+			continue
+		}
+		files, err := l.ReadFiles()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			childs[f.Name()] = &OverlayFile{File: f, parent: o, layer: i}
+		}
+	}
+	names := make([]string, 0, len(childs))
+	for k := range childs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	ret := make([]File, 0, len(names))
+	for _, n := range names {
+		ret = append(ret, childs[n])
+	}
+	return ret, nil
+}
+
+// Return the index of the layer that provides the file with the given
+// template name, ie.: "layout/main.html"
+func (o *OverlayDir) LayerOf(name string) (int, error) {
+	parts := strings.Split(name, "/")
+	cd := o
+	for _, p := range parts[:len(parts)-1] {
+		dirs, err := cd.ReadDirs()
+		if err != nil {
+			return -1, err
+		}
+		var next *OverlayDir
+		for _, d := range dirs {
+			if d.Name() == p {
+				next = d.(*OverlayDir)
+				break
+			}
+		}
+		if next == nil {
+			return -1, fmt.Errorf("%v not found", name)
+		}
+		cd = next
+	}
+	files, err := cd.ReadFiles()
+	if err != nil {
+		return -1, err
+	}
+	for _, f := range files {
+		if f.Name() == parts[len(parts)-1] {
+			return f.(*OverlayFile).Layer(), nil
+		}
+	}
+	return -1, fmt.Errorf("%v not found", name)
+}
+
+// Return the parent overlay, instead of the parent from the layer
+func (f *OverlayFile) Parent() Dir {
+	return f.parent
+}
+
+// Return the index of the layer that provided this file
+func (f *OverlayFile) Layer() int {
+	return f.layer
+}
+
+// Return the modification time from the file provided by the layer,
+// if that file isn't a ModTimer an error is returned
+func (f *OverlayFile) ModTime() (time.Time, error) {
+	if mt, ok := f.File.(ModTimer); ok {
+		return mt.ModTime()
+	}
+	return time.Time{}, errors.New("modification time not available")
+}
@@ -0,0 +1,72 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	base := MapVFS{
+		"layout/main.html": []byte(`base {{ template "index/index.html" . }}`),
+		"index/index.html": []byte(`index`),
+	}
+	theme := MapVFS{
+		"layout/main.html": []byte(`theme {{ template "index/index.html" . }}`),
+		"users/list.html":  []byte(`users`),
+	}
+	vfs := Overlay(base, theme)
+
+	dirs, err := vfs.ReadDirs()
+	if err != nil {
+		t.Fatalf("unable to read dirs %v", err)
+	}
+	if len(dirs) != 3 {
+		t.Fatalf("expecting 3 dirs but got %v", len(dirs))
+	}
+
+	set, err := LoadDir(vfs, nil, FilterFunc(AllowHtmlJsAndCss))
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	for _, name := range []string{"layout/main.html", "index/index.html", "users/list.html"} {
+		if _, has := set[name]; !has {
+			t.Errorf("%v should be in the set", name)
+		}
+	}
+
+	tmpl, err := Template(set, nil)
+	if err != nil {
+		t.Fatalf("unable to build template %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.ExecuteTemplate(buf, "layout/main.html", nil); err != nil {
+		t.Fatalf("unexpected error while rendering template %v", err)
+	}
+	if buf.String() != "theme index" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	for name, layer := range map[string]int{
+		"layout/main.html": 1,
+		"index/index.html": 0,
+		"users/list.html":  1,
+	} {
+		if l, err := vfs.LayerOf(name); err != nil || l != layer {
+			t.Errorf("%v should come from layer %v but got %v / %v", name, layer, l, err)
+		}
+	}
+	if _, err := vfs.LayerOf("layout/missing.html"); err == nil {
+		t.Errorf("missing files should return an error")
+	}
+}
@@ -0,0 +1,115 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"fmt"
+	"html/template"
+	"path"
+	"strconv"
+	"strings"
+	"text/template/parse"
+)
+
+// Files whose name starts with "_" are partials, ie.: "users/_row.html"
+//
+// Partials are small pieces of a view, they are rendered using the
+// partial function and shouldn't be rendered as views or layouts.
+//
+// The name passed to partial can omit the "_" and the extension, names
+// without a "/" are relative to the directory of the caller:
+//
+//	users/index.html
+//	{{ range .Users }}{{ partial "row" . }}{{ end }}
+//	{{ partial "shared/pager" "page" .Page "total" .Total }}
+//
+// Renders "users/_row.html" for each user and then "shared/_pager.html"
+// with a map holding the keys "page" and "total".
+//
+// Names given as string literals are resolved when the file is loaded,
+// other names are always relative to the root.
+func IsPartial(name string) bool {
+	return strings.HasPrefix(path.Base(name), "_")
+}
+
+// Return the template name of a partial called from the template
+// from (use "" for the root)
+func resolvePartial(from, name string) string {
+	if !strings.Contains(name, "/") {
+		name = path.Join(path.Dir(from), name)
+	}
+	dir, base := path.Split(path.Clean("/" + name))
+	if !strings.HasPrefix(base, "_") {
+		base = "_" + base
+	}
+	if path.Ext(base) == "" {
+		ext := path.Ext(from)
+		if ext == "" {
+			ext = ".html"
+		}
+		base += ext
+	}
+	return strings.TrimPrefix(dir+base, "/")
+}
+
+// Rewrite every call to partial with a literal name to use the
+// full name of the partial
+func resolvePartials(from string, tree *parse.Tree) {
+	if tree.Root == nil {
+		return
+	}
+	walkNodes(tree.Root, func(n parse.Node) {
+		if name := partialName(n); name != nil {
+			name.Text = resolvePartial(from, name.Text)
+			name.Quoted = strconv.Quote(name.Text)
+		}
+	})
+}
+
+// If n is a call to partial with a literal name, return the name node
+func partialName(n parse.Node) *parse.StringNode {
+	cmd, ok := n.(*parse.CommandNode)
+	if !ok || len(cmd.Args) < 2 {
+		return nil
+	}
+	if fn, ok := cmd.Args[0].(*parse.IdentifierNode); !ok || fn.Ident != "partial" {
+		return nil
+	}
+	name, _ := cmd.Args[1].(*parse.StringNode)
+	return name
+}
+
+// Return the partial function bound to t
+func partialFunc(t *template.Template) func(string, ...interface{}) (template.HTML, error) {
+	return func(name string, args ...interface{}) (template.HTML, error) {
+		var data interface{}
+		switch len(args) {
+		case 0:
+		case 1:
+			data = args[0]
+		default:
+			if len(args)%2 != 0 {
+				return "", fmt.Errorf("partial %v: expecting a value or key/value pairs", name)
+			}
+			values := make(map[string]interface{}, len(args)/2)
+			for i := 0; i < len(args); i += 2 {
+				key, ok := args[i].(string)
+				if !ok {
+					return "", fmt.Errorf("partial %v: key %v must be a string", name, args[i])
+				}
+				values[key] = args[i+1]
+			}
+			data = values
+		}
+		return executeHTML(t, resolvePartial("", name), data)
+	}
+}
@@ -0,0 +1,82 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestResolvePartial(t *testing.T) {
+	for _, c := range [][3]string{
+		{"users/index.html", "row", "users/_row.html"},
+		{"users/index.html", "_row.html", "users/_row.html"},
+		{"users/index.html", "shared/pager", "shared/_pager.html"},
+		{"users/index.html", "users/_row.html", "users/_row.html"},
+		{"index.html", "row", "_row.html"},
+		{"app.js", "util", "_util.js"},
+		{"", "users/row", "users/_row.html"},
+	} {
+		if got := resolvePartial(c[0], c[1]); got != c[2] {
+			t.Errorf("%v from %v should be %v but got %v", c[1], c[0], c[2], got)
+		}
+	}
+}
+
+func TestPartial(t *testing.T) {
+	set, err := LoadDir(MapVFS{
+		"users/index.html":    []byte(`{{ range .Users }}{{ partial "row" . }}{{ end }}{{ partial "shared/pager" "page" .Page "total" 2 }}`),
+		"users/_row.html":     []byte(`<li>{{ . }}</li>`),
+		"shared/_pager.html":  []byte(`{{ .page }}/{{ .total }}`),
+		"users/dynamic.html":  []byte(`{{ partial .Name }}`),
+		"users/missing.html":  []byte(`{{ partial "nope" }}`),
+		"shared/_empty.html":  []byte(`empty`),
+		"users/template.html": []byte(`{{ template "users/_row.html" "x" }}`),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	tmpl, err := Template(set, nil)
+	if err != nil {
+		t.Fatalf("unable to build template %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	err = tmpl.ExecuteTemplate(buf, "users/index.html", map[string]interface{}{
+		"Users": []string{"<a>", "b"},
+		"Page":  1,
+	})
+	if err != nil {
+		t.Fatalf("unable to render %v", err)
+	}
+	if buf.String() != "<li>&lt;a&gt;</li><li>b</li>1/2" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := tmpl.ExecuteTemplate(buf, "users/dynamic.html", map[string]string{"Name": "shared/empty"}); err != nil {
+		t.Fatalf("unable to render %v", err)
+	}
+	if buf.String() != "empty" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	err = Validate(set, nil)
+	verr, ok := err.(ValidationError)
+	if !ok || len(verr) != 1 || verr[0].Name != "users/_nope.html" {
+		t.Errorf("expecting users/_nope.html to be reported but got %v", err)
+	}
+
+	if !IsPartial("users/_row.html") || IsPartial("users/index.html") {
+		t.Errorf("only files starting with _ are partials")
+	}
+}
@@ -0,0 +1,153 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"html/template"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	tt "text/template"
+)
+
+// Cache the templates created by TemplateFuncs
+//
+// Templates are cached by set and alias map, the set is identified
+// by its address, so a TreeSet must not be changed after it is
+// used by a Renderer (the sets from a Loader are never changed).
+// Call Reset if you need to change the set.
+//
+// Templates from up to MaxSets sets are kept, when a new set is used
+// the templates from the least recently used set are discarded, so
+// the sets replaced by a Loader don't stay in memory.
+//
+// A Renderer is safe for concurrent use, the zero value is ready to use.
+type Renderer struct {
+	// Functions added to every template
+	Funcs tt.FuncMap
+	// If true, templates are executed with ExecuteContext
+	Contextual bool
+	// How many sets are cached, the default is 8
+	MaxSets int
+
+	mu    sync.RWMutex
+	sets  map[uintptr]*cachedSet
+	clock int64
+}
+
+// The templates built from a set
+type cachedSet struct {
+	// keep a reference, so the address isn't reused
+	// while the set is on the cache
+	set       TreeSet
+	templates map[string]*template.Template
+	// value of the clock when the set was last used
+	used int64
+}
+
+// Return the template for the given set and alias map, building it
+// only if it isn't on the cache
+func (r *Renderer) Template(set TreeSet, alias map[string]string) (*template.Template, error) {
+	id := setID(set)
+	key := aliasKey(set, alias)
+	r.mu.RLock()
+	if cs := r.sets[id]; cs != nil {
+		if t, has := cs.templates[key]; has {
+			atomic.StoreInt64(&cs.used, atomic.AddInt64(&r.clock, 1))
+			r.mu.RUnlock()
+			return t, nil
+		}
+	}
+	r.mu.RUnlock()
+
+	t, err := TemplateFuncs(set, alias, r.Funcs)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cs := r.sets[id]
+	if cs == nil {
+		r.evict()
+		cs = &cachedSet{set: set, templates: make(map[string]*template.Template)}
+		r.sets[id] = cs
+	}
+	atomic.StoreInt64(&cs.used, atomic.AddInt64(&r.clock, 1))
+	if cached, has := cs.templates[key]; has {
+		// someone else built it first
+		return cached, nil
+	}
+	cs.templates[key] = t
+	return t, nil
+}
+
+// Execute the template with the given name, using the cached template
+// for set and alias
+func (r *Renderer) ExecuteTemplate(w io.Writer, set TreeSet, alias map[string]string, name string, data interface{}) error {
+	t, err := r.Template(set, alias)
+	if err != nil {
+		return err
+	}
+	if r.Contextual {
+		return ExecuteContext(t, w, name, data)
+	}
+	return t.ExecuteTemplate(w, name, data)
+}
+
+// Discard all cached templates
+func (r *Renderer) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sets = nil
+}
+
+// Make room for a new set, must be called with the write lock
+func (r *Renderer) evict() {
+	if r.sets == nil {
+		r.sets = make(map[uintptr]*cachedSet)
+	}
+	max := r.MaxSets
+	if max <= 0 {
+		max = 8
+	}
+	for len(r.sets) >= max {
+		var oldest uintptr
+		var used int64 = -1
+		for id, cs := range r.sets {
+			if u := atomic.LoadInt64(&cs.used); used < 0 || u < used {
+				oldest, used = id, u
+			}
+		}
+		delete(r.sets, oldest)
+	}
+}
+
+func setID(set TreeSet) uintptr {
+	return reflect.ValueOf(set).Pointer()
+}
+
+// Normalize the alias map, ignoring the entries that point
+// to templates that don't exist (Template also ignores them)
+func aliasKey(set TreeSet, alias map[string]string) string {
+	keys := make([]string, 0, len(alias))
+	for k, v := range alias {
+		if _, has := set[v]; has {
+			keys = append(keys, k+"\x00"+v)
+		}
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\x00")
+}
@@ -0,0 +1,88 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	tt "text/template"
+)
+
+func TestRenderer(t *testing.T) {
+	funcs := tt.FuncMap{"upper": strings.ToUpper}
+	set, err := LoadDir(MapVFS{
+		"layout/main.html": []byte(`<p>{{ template "contents" . }}</p>`),
+		"index/index.html": []byte(`{{ upper . }}`),
+		"users/list.html":  []byte(`users`),
+	}, funcs, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+
+	r := &Renderer{Funcs: funcs}
+	alias := map[string]string{"main": "layout/main.html", "contents": "index/index.html", "missing": "nope.html"}
+	first, err := r.Template(set, alias)
+	if err != nil {
+		t.Fatalf("unable to build template %v", err)
+	}
+	second, _ := r.Template(set, map[string]string{"contents": "index/index.html", "main": "layout/main.html"})
+	if first != second {
+		t.Errorf("the same alias map should return the cached template")
+	}
+	third, _ := r.Template(set, map[string]string{"main": "layout/main.html", "contents": "users/list.html"})
+	if first == third {
+		t.Errorf("a different alias map should return a different template")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := &bytes.Buffer{}
+			if err := r.ExecuteTemplate(buf, set, alias, "main", "<hi>"); err != nil {
+				t.Errorf("unable to render %v", err)
+			}
+			if buf.String() != "<p>&lt;HI&gt;</p>" {
+				t.Errorf("unexpected output %q", buf.String())
+			}
+		}()
+	}
+	wg.Wait()
+
+	other := make(TreeSet)
+	for k, v := range set {
+		other[k] = v
+	}
+	fromOther, _ := r.Template(other, alias)
+	if fromOther == first {
+		t.Errorf("a different set should build a new template")
+	}
+	if again, _ := r.Template(set, alias); again != first {
+		t.Errorf("using another set should not discard the templates of the first one")
+	}
+
+	r.MaxSets = 2
+	another := make(TreeSet)
+	for k, v := range set {
+		another[k] = v
+	}
+	r.Template(another, alias)
+	if again, _ := r.Template(set, alias); again != first {
+		t.Errorf("the most recently used set should be kept")
+	}
+	if again, _ := r.Template(other, alias); again == fromOther || len(r.sets) != 2 {
+		t.Errorf("the least recently used set should be discarded, cached sets: %v", len(r.sets))
+	}
+}
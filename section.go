@@ -0,0 +1,80 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"html/template"
+	"strings"
+	"text/template/parse"
+)
+
+// Return the template name of a section defined inside a file
+//
+// Sections are named regions that a view fills and its layout renders,
+// like content_for from rails. A section is defined with a name starting
+// with "@" and rendered with yield:
+//
+//	users/index.html
+//	{{ define "@head" }}<link rel="stylesheet" href="users.css">{{ end }}
+//	<ul>...</ul>
+//
+//	layout/main.html
+//	{{ define "@head" }}<link rel="stylesheet" href="default.css">{{ end }}
+//	<html><head>{{ yield "head" . }}</head>
+//	<body>{{ template "contents" . }}{{ yield "scripts" . }}</body></html>
+//
+// While loading, "@head" from users/index.html is renamed to
+// "users/index.html@head", so every file can define its own version.
+// SectionAlias selects which version is used, if nobody defines a
+// section, yield renders nothing.
+func SectionName(file, section string) string {
+	return file + "@" + section
+}
+
+// Return the alias map that links each section to the first file from
+// chain that defines it, use the chain from LayoutChain (starting with the view)
+// so the view overrides the defaults from its layouts.
+func SectionAlias(set TreeSet, chain []string) map[string]string {
+	alias := make(map[string]string)
+	for i := len(chain) - 1; i >= 0; i-- {
+		prefix := chain[i] + "@"
+		for k := range set {
+			if strings.HasPrefix(k, prefix) {
+				alias["@"+k[len(prefix):]] = k
+			}
+		}
+	}
+	return alias
+}
+
+// Rename the sections defined by the file, returns the new name
+func renameSection(file, name string, tree *parse.Tree) string {
+	if !strings.HasPrefix(name, "@") {
+		return name
+	}
+	tree.Name = SectionName(file, name[1:])
+	return tree.Name
+}
+
+// Return the yield function bound to t
+func yieldFunc(t *template.Template) func(string, ...interface{}) (template.HTML, error) {
+	return func(name string, data ...interface{}) (template.HTML, error) {
+		if t.Lookup("@"+name) == nil {
+			return "", nil
+		}
+		var value interface{}
+		if len(data) > 0 {
+			value = data[0]
+		}
+		return executeHTML(t, "@"+name, value)
+	}
+}
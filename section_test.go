@@ -0,0 +1,54 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSections(t *testing.T) {
+	set, err := LoadDir(MapVFS{
+		"layout/main.html": []byte(`{{ define "@head" }}<default>{{ end }}` +
+			`<head>{{ yield "head" . }}</head><body>{{ template "contents" . }}{{ yield "scripts" }}</body>`),
+		"users/index.html": []byte(`{{ define "@scripts" }}<script src="{{ . }}"></script>{{ end }}users`),
+		"users/edit.html":  []byte(`{{ define "@head" }}<edit>{{ end }}edit`),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+	if _, has := set["users/index.html@scripts"]; !has {
+		t.Fatalf("sections should be renamed")
+	}
+
+	for view, expected := range map[string]string{
+		"users/index.html": `<head><default></head><body>users<script src=""></script></body>`,
+		"users/edit.html":  `<head><edit></head><body>edit</body>`,
+	} {
+		chain := []string{view, "layout/main.html"}
+		alias := ChainAlias(chain)
+		for k, v := range SectionAlias(set, chain) {
+			alias[k] = v
+		}
+		tmpl, err := Template(set, alias)
+		if err != nil {
+			t.Fatalf("unable to build template %v", err)
+		}
+		buf := &bytes.Buffer{}
+		if err := tmpl.ExecuteTemplate(buf, "main", "app.js"); err != nil {
+			t.Fatalf("unable to render %v", err)
+		}
+		if buf.String() != expected {
+			t.Errorf("%v should render %q but got %q", view, expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// A {{ template }} call whose target can't be found
+type Unresolved struct {
+	// The template being called
+	Name string
+	// The template that makes the call
+	From string
+	// Where the call happens (file:line:col)
+	Location string
+}
+
+func (u *Unresolved) String() string {
+	return u.Location + ": template " + u.Name + " not found"
+}
+
+// Every unresolved call found by Validate
+type ValidationError []*Unresolved
+
+func (v ValidationError) Error() string {
+	lines := make([]string, len(v))
+	for i, u := range v {
+		lines[i] = u.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Check if every {{ template "x" }} call inside set can be resolved,
+// either by a template with the same name or by a entry in the
+// alias map pointing to a template from the set.
+//
+// Calls to partial using a literal name are also checked.
+//
+// Returns nil if everything can be resolved or a ValidationError
+// otherwise. Use it before rendering to avoid sending half a page to
+// the client.
+func Validate(set TreeSet, alias map[string]string) error {
+	names := make([]string, 0, len(set))
+	for k := range set {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var ret ValidationError
+	for _, name := range names {
+		tree := set[name]
+		if tree.Root == nil {
+			continue
+		}
+		walkNodes(tree.Root, func(n parse.Node) {
+			var target string
+			switch n := n.(type) {
+			case *parse.TemplateNode:
+				target = n.Name
+			case *parse.CommandNode:
+				if pn := partialName(n); pn != nil {
+					target = resolvePartial("", pn.Text)
+				} else {
+					return
+				}
+			default:
+				return
+			}
+			if resolves(set, alias, target) {
+				return
+			}
+			loc, _ := tree.ErrorContext(n)
+			ret = append(ret, &Unresolved{Name: target, From: name, Location: loc})
+		})
+	}
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
+
+func resolves(set TreeSet, alias map[string]string, name string) bool {
+	if _, has := set[name]; has {
+		return true
+	}
+	if target, has := alias[name]; has {
+		_, has = set[target]
+		return has
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package webview
+
+// The MIT License (MIT)
+//
+// Copyright (c) 2013 Andre Luiz Alves Moraes
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	set, err := LoadDir(MapVFS{
+		"layout/main.html": []byte("<html>\n{{ if . }}{{ template \"contents\" . }}{{ end }}</html>"),
+		"index/index.html": []byte(`{{ range . }}{{ template "row" . }}{{ end }}`),
+		"index/row.html":   []byte(`{{ define "row" }}{{ . }}{{ end }}`),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to load template %v", err)
+	}
+
+	if err := Validate(set, map[string]string{"contents": "index/index.html"}); err != nil {
+		t.Errorf("everything should resolve but got %v", err)
+	}
+
+	err = Validate(set, map[string]string{"contents": "index/missing.html"})
+	verr, ok := err.(ValidationError)
+	if !ok || len(verr) != 1 {
+		t.Fatalf("expecting one unresolved call but got %v", err)
+	}
+	if verr[0].Name != "contents" || verr[0].From != "layout/main.html" || verr[0].Location != "layout/main.html:2:22" {
+		t.Errorf("unexpected report %#v", verr[0])
+	}
+	if verr.Error() != "layout/main.html:2:22: template contents not found" {
+		t.Errorf("unexpected message %q", verr.Error())
+	}
+}